@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/calculi-corp/workflow-advisor/generate"
+	"github.com/spf13/cobra"
+)
+
+const outputTable = "table"
+
+func DetectCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "detect",
+		Short: "Detect technologies without generating a workflow",
+		Long:  `Detect technologies and report how they are built without writing a workflow`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			generators, _ := cmd.Flags().GetStringSlice("generator")
+			exclude, _ := cmd.Flags().GetStringSlice("exclude")
+			src, _ := cmd.Flags().GetString("src")
+			output, _ := cmd.Flags().GetString("output")
+
+			if output != outputTable && output != outputJSON {
+				return fmt.Errorf("unsupported output format '%s', expected one of: %s, %s", output, outputTable, outputJSON)
+			}
+
+			detections, err := generate.Detect(context.Background(), src, generators, exclude)
+			if err != nil {
+				return err
+			}
+
+			if output == outputJSON {
+				if detections == nil {
+					detections = []generate.Detection{}
+				}
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(detections)
+			}
+			return printDetections(cmd.OutOrStdout(), detections)
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.Flags().StringSliceP("generator", "g", []string{}, "Generators to run detection for, 'auto' or omitted runs all of them")
+	cmd.Flags().StringSlice("exclude", []string{}, "Generators to skip")
+
+	cmd.Flags().String("src", "", "Source directory to scan")
+	cmd.MarkFlagRequired("src")
+
+	cmd.Flags().StringP("output", "o", outputTable, "Output format, one of: table, json")
+
+	return &cmd
+}
+
+func printDetections(w io.Writer, detections []generate.Detection) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GENERATOR\tTECHNOLOGY\tPROJECT\tVERSION\tBUILD TOOL\tPACKAGE MANAGER\tFRAMEWORK\tCONFIDENCE\tEVIDENCE")
+	for _, d := range detections {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.2f\t%s\n",
+			d.Generator,
+			d.Technology,
+			d.Project,
+			valueOrDash(d.Version),
+			valueOrDash(d.BuildTool),
+			valueOrDash(d.PackageManager),
+			valueOrDash(d.Framework),
+			d.Confidence,
+			strings.Join(d.Evidence, ","),
+		)
+	}
+	return tw.Flush()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
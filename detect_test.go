@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/calculi-corp/workflow-advisor/generate"
+)
+
+func Test_Detect(t *testing.T) {
+	_, srcDir := initTest(t, []string{"go.mod", "go.sum", "pom.xml"})
+
+	var stdout bytes.Buffer
+	cmd := GenerateCommand()
+	cmd.SetArgs([]string{"detect", "--src", srcDir})
+	cmd.SetOut(&stdout)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	require.Equal(t, `GENERATOR  TECHNOLOGY  PROJECT  VERSION  BUILD TOOL  PACKAGE MANAGER  FRAMEWORK  CONFIDENCE  EVIDENCE
+go         Go          .        1.26     go          go modules       -          1.00        go.mod,go.sum
+java       Java        .        21       maven       -                -          1.00        pom.xml
+`, stdout.String())
+}
+
+func Test_DetectJSON(t *testing.T) {
+	_, srcDir := initTest(t, []string{"go.mod", "go.sum", "pom.xml"})
+
+	var stdout bytes.Buffer
+	cmd := GenerateCommand()
+	cmd.SetArgs([]string{"detect", "--src", srcDir, "-g", "java", "-o", "json"})
+	cmd.SetOut(&stdout)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	var detections []generate.Detection
+	err = json.Unmarshal(stdout.Bytes(), &detections)
+	require.NoError(t, err)
+	require.Equal(t, []generate.Detection{
+		{
+			Generator:  "java",
+			Technology: "Java",
+			Project:    ".",
+			Version:    "21",
+			BuildTool:  "maven",
+			Evidence:   []string{"pom.xml"},
+			Confidence: 1,
+		},
+	}, detections)
+}
@@ -2,12 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/calculi-corp/workflow-advisor/generate"
+	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	outputYAML = "yaml"
+	outputJSON = "json"
+)
+
 func GenerateCommand() *cobra.Command {
 	cmd := cobra.Command{
 		Use:   os.Args[0],
@@ -15,19 +23,74 @@ func GenerateCommand() *cobra.Command {
 		Long:  `Detect technologies and generate workflow`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			generators, _ := cmd.Flags().GetStringSlice("generator")
+			exclude, _ := cmd.Flags().GetStringSlice("exclude")
 			workflow, _ := cmd.Flags().GetString("workflow")
 			src, _ := cmd.Flags().GetString("src")
+			output, _ := cmd.Flags().GetString("output")
+			onConflict, _ := cmd.Flags().GetString("on-conflict")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			diff, _ := cmd.Flags().GetBool("diff")
+
+			if output != outputYAML && output != outputJSON {
+				return fmt.Errorf("unsupported output format '%s', expected one of: %s, %s", output, outputYAML, outputJSON)
+			}
+			if diff && output == outputJSON {
+				return fmt.Errorf("--diff can not be combined with --output %s", outputJSON)
+			}
+
+			var current []byte
+			if diff {
+				var err error
+				current, err = utils.ReadFileIfExists(workflow)
+				if err != nil {
+					return err
+				}
+			}
+
+			conflictStrategy, err := generate.ParseConflictStrategy(onConflict)
+			if err != nil {
+				return err
+			}
 
-			return generate.Generate(context.Background(), workflow, src, generators)
+			out, err := generate.Generate(context.Background(), generate.Options{
+				WorkflowPath: workflow,
+				SrcDir:       src,
+				Generators:   generators,
+				Exclude:      exclude,
+				OnConflict:   conflictStrategy,
+				DryRun:       dryRun || diff,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case diff:
+				d, err := utils.UnifiedDiff(workflow, current, []byte(out.Workflow))
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprint(cmd.OutOrStdout(), d)
+				return err
+			case output == outputJSON:
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(out)
+			case dryRun:
+				_, err = fmt.Fprint(cmd.OutOrStdout(), out.Workflow)
+				return err
+			}
+			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 
 	cmd.SetFlagErrorFunc(handleError)
+	cmd.AddCommand(DetectCommand())
 
-	cmd.Flags().StringSliceP("generator", "g", []string{}, "Generators to run")
-	cmd.MarkFlagRequired("generator")
+	cmd.Flags().StringSliceP("generator", "g", []string{generate.AutoGenerators}, "Generators to run, 'auto' runs every generator detecting its technology")
+	cmd.Flags().StringSlice("exclude", []string{}, "Generators to skip, useful with auto detection")
 
 	cmd.Flags().StringP("workflow", "w", "", "Workflow file to use, created if not exists")
 	cmd.MarkFlagRequired("workflow")
@@ -35,6 +98,13 @@ func GenerateCommand() *cobra.Command {
 	cmd.Flags().String("src", "", "Workflow file to use, created if not exists")
 	cmd.MarkFlagRequired("workflow")
 
+	cmd.Flags().String("on-conflict", string(generate.ConflictFail), "What to do when a generated job already exists in the workflow, one of: fail, skip, replace, merge-steps, rename")
+
+	cmd.Flags().Bool("dry-run", false, "Print the resulting workflow to stdout instead of writing the workflow file")
+	cmd.Flags().Bool("diff", false, "Print a unified diff between the workflow file and the resulting workflow instead of writing it")
+
+	cmd.Flags().StringP("output", "o", outputYAML, "Output format, one of: yaml, json, json prints the advisor output document to stdout")
+
 	return &cmd
 }
 
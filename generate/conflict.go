@@ -0,0 +1,102 @@
+package generate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+)
+
+// ConflictStrategy defines what happens when a generated job already exists in the workflow
+type ConflictStrategy string
+
+const (
+	// ConflictFail returns an error, the workflow is left untouched
+	ConflictFail ConflictStrategy = "fail"
+	// ConflictSkip keeps the existing job and drops the generated one
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictReplace overwrites the existing job with the generated one
+	ConflictReplace ConflictStrategy = "replace"
+	// ConflictMergeSteps keeps the existing job and adds the generated steps it does not contain yet
+	ConflictMergeSteps ConflictStrategy = "merge-steps"
+	// ConflictRename adds the generated job under the first free name with a numeric suffix
+	ConflictRename ConflictStrategy = "rename"
+)
+
+// ConflictStrategies lists every supported strategy
+var ConflictStrategies = []ConflictStrategy{ConflictFail, ConflictSkip, ConflictReplace, ConflictMergeSteps, ConflictRename}
+
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	strategy := ConflictStrategy(s)
+	if !slices.Contains(ConflictStrategies, strategy) {
+		names := make([]string, 0, len(ConflictStrategies))
+		for _, c := range ConflictStrategies {
+			names = append(names, string(c))
+		}
+		return "", fmt.Errorf("unsupported conflict strategy '%s', expected one of: %s", s, strings.Join(names, ", "))
+	}
+	return strategy, nil
+}
+
+// addJob adds job to the workflow, an existing job with the same name is resolved according to OnConflict
+func (c *WorkflowContext) addJob(name string, job dsl.Job) error {
+	wf := c.Workflow
+	if wf.Jobs == nil {
+		wf.Jobs = make(map[string]dsl.Job)
+	}
+
+	existing, ok := wf.Jobs[name]
+	if !ok {
+		wf.Jobs[name] = job
+		return nil
+	}
+
+	switch c.OnConflict {
+	case ConflictSkip:
+		return nil
+	case ConflictReplace:
+		wf.Jobs[name] = job
+	case ConflictMergeSteps:
+		existing.Steps = mergeSteps(existing.Steps, job.Steps)
+		wf.Jobs[name] = existing
+	case ConflictRename:
+		for i := 2; ; i++ {
+			renamed := fmt.Sprintf("%s-%d", name, i)
+			if _, ok := wf.Jobs[renamed]; !ok {
+				wf.Jobs[renamed] = job
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("error adding job: job %s already exists", name)
+	}
+
+	return nil
+}
+
+// mergeSteps returns existing with every step of generated it does not contain yet, steps are matched by name.
+// Missing steps are inserted after the last matched step preceding them to keep the generated order.
+func mergeSteps(existing, generated []dsl.Step) []dsl.Step {
+	res := slices.Clone(existing)
+	pos := 0
+	for _, step := range generated {
+		idx := slices.IndexFunc(res, func(s dsl.Step) bool {
+			return sameStep(s, step)
+		})
+		if idx >= 0 {
+			pos = idx + 1
+			continue
+		}
+		res = slices.Insert(res, pos, step)
+		pos++
+	}
+	return res
+}
+
+func sameStep(a, b dsl.Step) bool {
+	if a.Name != "" || b.Name != "" {
+		return a.Name == b.Name
+	}
+	return a.Uses == b.Uses && a.Run == b.Run
+}
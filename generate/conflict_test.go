@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddJobConflict(t *testing.T) {
+	existing := dsl.Job{
+		Steps: []dsl.Step{
+			{Name: "checkout", Uses: checkoutAction},
+			{Name: "custom", Uses: "docker://alpine", Run: "echo custom"},
+			{Name: "test", Uses: "docker://golang", Run: "go test -v ./..."},
+		},
+	}
+	generated := dsl.Job{
+		Steps: []dsl.Step{
+			{Name: "checkout", Uses: checkoutAction},
+			{Name: "test", Uses: "docker://golang", Run: "go test ./..."},
+			{Name: "build", Uses: "docker://golang", Run: "go build ./..."},
+			{Name: "scan", Uses: "scan-action"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		strategy  ConflictStrategy
+		expected  map[string]dsl.Job
+		wantError string
+	}{
+		{
+			name:      "fail",
+			strategy:  ConflictFail,
+			wantError: "error adding job: job go-build already exists",
+		},
+		{
+			name:      "default fails",
+			wantError: "error adding job: job go-build already exists",
+		},
+		{
+			name:     "skip",
+			strategy: ConflictSkip,
+			expected: map[string]dsl.Job{"go-build": existing},
+		},
+		{
+			name:     "replace",
+			strategy: ConflictReplace,
+			expected: map[string]dsl.Job{"go-build": generated},
+		},
+		{
+			name:     "merge-steps",
+			strategy: ConflictMergeSteps,
+			expected: map[string]dsl.Job{
+				"go-build": {
+					Steps: []dsl.Step{
+						{Name: "checkout", Uses: checkoutAction},
+						{Name: "custom", Uses: "docker://alpine", Run: "echo custom"},
+						{Name: "test", Uses: "docker://golang", Run: "go test -v ./..."},
+						{Name: "build", Uses: "docker://golang", Run: "go build ./..."},
+						{Name: "scan", Uses: "scan-action"},
+					},
+				},
+			},
+		},
+		{
+			name:     "rename",
+			strategy: ConflictRename,
+			expected: map[string]dsl.Job{
+				"go-build":   existing,
+				"go-build-2": generated,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wc := &WorkflowContext{
+				Workflow:   baseWorkflow(),
+				OnConflict: tt.strategy,
+			}
+			wc.Workflow.Jobs = map[string]dsl.Job{"go-build": existing}
+
+			err := wc.addJob("go-build", generated)
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, wc.Workflow.Jobs)
+		})
+	}
+}
+
+func TestParseConflictStrategy(t *testing.T) {
+	strategy, err := ParseConflictStrategy("merge-steps")
+	require.NoError(t, err)
+	require.Equal(t, ConflictMergeSteps, strategy)
+
+	_, err = ParseConflictStrategy("overwrite")
+	require.EqualError(t, err, "unsupported conflict strategy 'overwrite', expected one of: fail, skip, replace, merge-steps, rename")
+}
@@ -2,13 +2,16 @@ package generate
 
 import (
 	"context"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 )
 
 var (
@@ -16,11 +19,16 @@ var (
 	solutionExtension  = ".sln"
 	solutionFileHeader = "Microsoft Visual Studio Solution File"
 	defaultVersion     = "net8.0"
-	supportedVersions  = map[string]string{
-		"net8.0": "docker://mcr.microsoft.com/dotnet/sdk:8.0",
-		"net7.0": "docker://mcr.microsoft.com/dotnet/sdk:7.0",
-		"net6.0": "docker://mcr.microsoft.com/dotnet/sdk:6.0",
-		"net5.0": "docker://mcr.microsoft.com/dotnet/sdk:5.0",
+	defaultSdkVersion  = 8
+	// newestSdkVersion is used for projects targeting a version of .NET newer than the supported ones
+	newestSdkVersion  = 10
+	supportedVersions = map[int]string{
+		10: "docker://mcr.microsoft.com/dotnet/sdk:10.0",
+		9:  "docker://mcr.microsoft.com/dotnet/sdk:9.0",
+		8:  "docker://mcr.microsoft.com/dotnet/sdk:8.0",
+		7:  "docker://mcr.microsoft.com/dotnet/sdk:7.0",
+		6:  "docker://mcr.microsoft.com/dotnet/sdk:6.0",
+		5:  "docker://mcr.microsoft.com/dotnet/sdk:5.0",
 	}
 )
 
@@ -35,13 +43,27 @@ type CsProj struct {
 }
 
 type PropertyGroup struct {
-	TargetFramework string `xml:"TargetFramework"`
+	TargetFramework  string `xml:"TargetFramework"`
+	TargetFrameworks string `xml:"TargetFrameworks"`
+}
+
+// GlobalJSON is the global.json file selecting the .NET SDK
+type GlobalJSON struct {
+	Sdk struct {
+		Version     string `json:"version"`
+		RollForward string `json:"rollForward"`
+	} `json:"sdk"`
 }
 
 type csharpContext struct {
 	isCSharpRepo bool
-	Version      string
-	solutions    []string
+	// Version is the newest framework targeted by the projects
+	Version string
+	// sdkVersion is the major version of the SDK able to build every targeted framework
+	sdkVersion int
+	projects   []string
+	solutions  []string
+	globalJSON string
 }
 
 func init() {
@@ -50,32 +72,44 @@ func init() {
 	})
 }
 
-func (g *csharp) Generate(ctx context.Context, workflowContext *WorkflowContext) error {
-	csContext, err := g.detectTech(workflowContext.SrcDir)
+func (g *csharp) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	csContext, err := g.detectTech(srcDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !csContext.isCSharpRepo {
-		return nil
+		return nil, nil
 	}
 
-	err = g.generateJob(workflowContext.Workflow, csContext)
-
-	return err
+	return []Detection{
+		{
+			Generator:      "csharp",
+			Technology:     "C#",
+			Project:        rootProject,
+			Version:        csContext.Version,
+			BuildTool:      "dotnet",
+			PackageManager: "nuget",
+			Evidence:       relPaths(srcDir, csContext.evidence()...),
+			Confidence:     confidenceHigh,
+			details:        csContext,
+		},
+	}, nil
 }
 
-func (g *csharp) generateJob(workflow *dsl.Workflow, csContext csharpContext) error {
-	if workflow.Jobs == nil {
-		workflow.Jobs = make(map[string]dsl.Job)
+func (g *csharp) Generate(ctx context.Context, workflowContext *WorkflowContext, detections []Detection) error {
+	for _, d := range detections {
+		csContext, _ := d.details.(csharpContext)
+		err := g.generateJob(workflowContext, csContext)
+		if err != nil {
+			return err
+		}
 	}
 
-	_, ok := workflow.Jobs[g.jobName]
-
-	if ok {
-		return fmt.Errorf("error adding job %s, it already exists, csharp generator is not compatible with workflow provided", g.jobName)
-	}
+	return nil
+}
 
+func (g *csharp) generateJob(workflowContext *WorkflowContext, csContext csharpContext) error {
 	job := dsl.Job{
 		Steps: []dsl.Step{
 			{
@@ -85,7 +119,7 @@ func (g *csharp) generateJob(workflow *dsl.Workflow, csContext csharpContext) er
 		},
 	}
 
-	image := g.getImage(csContext.Version)
+	image := g.getImage(csContext.sdkVersion)
 
 	if len(csContext.solutions) > 0 {
 		for _, solution := range csContext.solutions {
@@ -131,18 +165,27 @@ find . -name "*.csproj" -print0 | xargs -0 dotnet sln add`,
 			"language": "LANGUAGE_DOTNET"},
 	})
 
-	workflow.Jobs[g.jobName] = job
-
-	return nil
+	return workflowContext.addJob(g.jobName, job)
 }
 
-func (g *csharp) getImage(version string) string {
-	val, ok := supportedVersions[version]
+func (g *csharp) getImage(sdkVersion int) string {
+	val, ok := supportedVersions[sdkVersion]
 	if ok {
 		return val
 	}
+	if sdkVersion > newestSdkVersion {
+		return supportedVersions[newestSdkVersion]
+	}
 
-	return supportedVersions[defaultVersion]
+	return supportedVersions[defaultSdkVersion]
+}
+
+func (c csharpContext) evidence() []string {
+	evidence := append(append([]string{}, c.projects...), c.solutions...)
+	if c.globalJSON != "" {
+		evidence = append(evidence, c.globalJSON)
+	}
+	return evidence
 }
 
 func (g *csharp) detectTech(folder string) (csharpContext, error) {
@@ -168,18 +211,27 @@ func (g *csharp) detectTech(folder string) (csharpContext, error) {
 	}
 
 	for _, file := range files {
-		iscs, version := g.isSdkProj(file)
+		iscs, frameworks := g.isSdkProj(file)
 		if iscs {
 			res.isCSharpRepo = true
-			_, ok := supportedVersions[version]
-			if ok && version > res.Version {
-				res.Version = version
+			res.projects = append(res.projects, file)
+			for _, framework := range frameworks {
+				version, ok := parseTargetFramework(framework)
+				if ok && version > res.sdkVersion {
+					res.sdkVersion = version
+					res.Version = framework
+				}
 			}
 		}
 	}
 
 	if len(res.Version) == 0 {
 		res.Version = defaultVersion
+		res.sdkVersion = defaultSdkVersion
+	}
+
+	if err := g.applyGlobalJSON(folder, &res); err != nil {
+		return res, err
 	}
 
 	solutions, err := g.findSolutions(folder)
@@ -216,27 +268,84 @@ func (g *csharp) findSolutions(folder string) ([]string, error) {
 	return files, err
 }
 
-func (g *csharp) isSdkProj(path string) (bool, string) {
+// applyGlobalJSON selects the SDK pinned by global.json in folder, unless the SDK may roll forward to a newer major
+// version which is needed by the targeted frameworks
+func (g *csharp) applyGlobalJSON(folder string, res *csharpContext) error {
+	file := filepath.Join(folder, "global.json")
+	b, err := utils.ReadFileIfExists(file)
+	if err != nil || b == nil {
+		return err
+	}
+
+	var global GlobalJSON
+	if err := json.Unmarshal(b, &global); err != nil {
+		// ignore global.json in unknown format, e.g. with comments
+		return nil
+	}
+	majorStr, _, _ := strings.Cut(global.Sdk.Version, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return nil
+	}
+
+	res.globalJSON = file
+	if global.Sdk.RollForward == "latestMajor" && res.sdkVersion > major {
+		return nil
+	}
+	res.sdkVersion = major
+	return nil
+}
+
+// isSdkProj returns whether the project in path is an SDK-style project and the frameworks it targets
+func (g *csharp) isSdkProj(path string) (bool, []string) {
 	var proj CsProj
 	projBytes, err := os.ReadFile(path)
 	if err != nil {
 		// ignore project if can not read it
-		return false, ""
+		return false, nil
 	}
 	if err := xml.Unmarshal(projBytes, &proj); err != nil {
 		// ignore project if it has unknown format
-		return false, ""
+		return false, nil
 	}
 	if len(proj.Sdk) > 0 {
-		version := defaultVersion
+		var frameworks []string
 		for _, prop := range proj.PropertyGroups {
 			if len(prop.TargetFramework) > 0 {
-				version = prop.TargetFramework
-				break
+				frameworks = append(frameworks, strings.TrimSpace(prop.TargetFramework))
+			}
+			// multi-targeting projects list the frameworks separated by semicolons
+			for _, framework := range strings.Split(prop.TargetFrameworks, ";") {
+				if framework = strings.TrimSpace(framework); len(framework) > 0 {
+					frameworks = append(frameworks, framework)
+				}
 			}
 		}
-		return true, version
+		return true, frameworks
 	}
 
-	return false, ""
+	return false, nil
+}
+
+// parseTargetFramework returns the major version of .NET a target framework moniker such as net8.0,
+// net8.0-windows or netcoreapp3.1 refers to. .NET Standard and .NET Framework monikers are built by any SDK and
+// are not reported.
+func parseTargetFramework(framework string) (int, bool) {
+	framework = strings.ToLower(framework)
+	// OS specific frameworks, e.g. net8.0-android
+	framework, _, _ = strings.Cut(framework, "-")
+	version, ok := strings.CutPrefix(framework, "netcoreapp")
+	if !ok {
+		version, ok = strings.CutPrefix(framework, "net")
+		// .NET Framework monikers have no dot, e.g. net48
+		if !ok || !strings.Contains(version, ".") {
+			return 0, false
+		}
+	}
+	majorStr, _, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return 0, false
+	}
+	return major, true
 }
@@ -57,6 +57,16 @@ func TestCSharp(t *testing.T) {
 			expectedPath: "testdata/csharp/expected/multiple-versions.yaml",
 			copyToTemp:   true,
 		},
+		{
+			name:         "multi-targeting",
+			src:          "testdata/csharp/input/multi-targeting",
+			expectedPath: "testdata/csharp/expected/multi-targeting.yaml",
+		},
+		{
+			name:         "global-json",
+			src:          "testdata/csharp/input/global-json",
+			expectedPath: "testdata/csharp/expected/global-json.yaml",
+		},
 	}
 
 	for _, tt := range tests {
@@ -76,7 +86,10 @@ func TestCSharp(t *testing.T) {
 				Workflow: baseWorkflow(),
 			}
 
-			err := gen.Generate(context.Background(), wContext)
+			detections, err := gen.Detect(context.Background(), baseDir)
+			require.NoError(t, err)
+
+			err = gen.Generate(context.Background(), wContext, detections)
 			require.NoError(t, err)
 
 			actual := wContext.Workflow
@@ -95,6 +108,30 @@ func TestCSharp(t *testing.T) {
 	}
 }
 
+func TestParseTargetFramework(t *testing.T) {
+	tests := []struct {
+		framework string
+		expected  int
+		ok        bool
+	}{
+		{framework: "net10.0", expected: 10, ok: true},
+		{framework: "net9.0", expected: 9, ok: true},
+		{framework: "net8.0-windows10.0.19041.0", expected: 8, ok: true},
+		{framework: "netcoreapp3.1", expected: 3, ok: true},
+		{framework: "netstandard2.1"},
+		{framework: "net48"},
+		{framework: "$(DefaultTargetFramework)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.framework, func(t *testing.T) {
+			version, ok := parseTargetFramework(tt.framework)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
 func copyDir(src, dst string) error {
 	info, err := os.Stat(src)
 	if err != nil {
@@ -0,0 +1,125 @@
+package generate
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+	"github.com/calculi-corp/workflow-advisor/pkg/utils"
+)
+
+// denoImage is pinned so a new major release of Deno does not change how the generated workflow behaves
+const denoImage = "docker://denoland/deno:2.5.0"
+
+// denoProject holds what was detected about a project configured by deno.json
+type denoProject struct {
+	tasks    map[string]json.RawMessage
+	lockfile bool
+}
+
+// denoFallbacks are run for steps without a task in deno.json
+var denoFallbacks = map[string]string{
+	"lint": "deno lint",
+	"test": "deno test --allow-all",
+}
+
+// detectDenoProject detects the deno.json project in projectDir
+func detectDenoProject(srcDir, projectDir string) (Detection, error) {
+	var evidence []string
+	var cfg struct {
+		// tasks are either a command or an object with the command and dependencies
+		Tasks map[string]json.RawMessage `json:"tasks"`
+	}
+	for _, f := range []string{"deno.json", "deno.jsonc"} {
+		file := filepath.Join(projectDir, f)
+		b, err := utils.ReadFileIfExists(file)
+		if err != nil {
+			return Detection{}, err
+		}
+		if b == nil {
+			continue
+		}
+		evidence = append(evidence, file)
+		// ignore configuration in unknown format, e.g. deno.jsonc with comments
+		_ = json.Unmarshal(b, &cfg)
+		break
+	}
+
+	// deno task runs package.json scripts as well
+	packageJSONFile := filepath.Join(projectDir, "package.json")
+	hasPackageJSON, err := utils.Stat(packageJSONFile)
+	if err != nil {
+		return Detection{}, err
+	}
+	if hasPackageJSON {
+		evidence = append(evidence, packageJSONFile)
+		pkg, err := readPackageJSON(packageJSONFile)
+		if err != nil {
+			return Detection{}, err
+		}
+		for name := range pkg.Scripts {
+			if _, ok := cfg.Tasks[name]; !ok && pkg.findScript(name) != "" {
+				if cfg.Tasks == nil {
+					cfg.Tasks = map[string]json.RawMessage{}
+				}
+				cfg.Tasks[name] = nil
+			}
+		}
+	}
+
+	lockfile := filepath.Join(projectDir, "deno.lock")
+	hasLockfile, err := utils.Stat(lockfile)
+	if err != nil {
+		return Detection{}, err
+	}
+	if hasLockfile {
+		evidence = append(evidence, lockfile)
+	}
+
+	return Detection{
+		Generator:      "js",
+		Technology:     "Deno",
+		BuildTool:      "deno",
+		PackageManager: "deno",
+		Evidence:       relPaths(srcDir, evidence...),
+		Confidence:     confidenceHigh,
+		details: denoProject{
+			tasks:    cfg.Tasks,
+			lockfile: hasLockfile,
+		},
+	}, nil
+}
+
+func (g *javascript) generateDenoProject(workflowContext *WorkflowContext, d Detection, p denoProject) error {
+	install := "deno install"
+	if p.lockfile {
+		install += " --frozen"
+	}
+
+	steps := []dsl.Step{
+		{
+			Name: "get dependencies",
+			Uses: denoImage,
+			Run:  install,
+		},
+	}
+	for _, s := range jsScriptSteps {
+		cmd := denoFallbacks[s.name]
+		for _, task := range s.scripts {
+			if _, ok := p.tasks[task]; ok {
+				cmd = "deno task " + task
+				break
+			}
+		}
+		if cmd == "" {
+			continue
+		}
+		steps = append(steps, dsl.Step{
+			Name: s.name,
+			Uses: denoImage,
+			Run:  cmd,
+		})
+	}
+
+	return g.addJob(workflowContext, g.denoJobName, d.Project, steps...)
+}
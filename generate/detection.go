@@ -0,0 +1,65 @@
+package generate
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// confidenceHigh is used when a build file of the technology was found
+	confidenceHigh = 1.0
+	// confidenceLow is used when only source files of the technology were found
+	confidenceLow = 0.5
+)
+
+// Detection describes a project found by a generator, how it is built and which files triggered detection.
+type Detection struct {
+	Generator      string   `json:"generator"`
+	Technology     string   `json:"technology"`
+	Project        string   `json:"project"`
+	Version        string   `json:"version,omitempty"`
+	Framework      string   `json:"framework,omitempty"`
+	BuildOutput    string   `json:"buildOutput,omitempty"`
+	BuildTool      string   `json:"buildTool,omitempty"`
+	PackageManager string   `json:"packageManager,omitempty"`
+	Modules        []string `json:"modules,omitempty"`
+	Evidence       []string `json:"evidence"`
+	Confidence     float64  `json:"confidence"`
+
+	// details holds generator specific data collected during detection and used to generate the job
+	details any
+}
+
+// Detect runs the detection phase of the named generators against srcDir without generating a workflow,
+// generator names are resolved the same way as Options.Generators
+func Detect(ctx context.Context, srcDir string, generatorNames []string, exclude []string) ([]Detection, error) {
+	genPipeline, err := buildPipeline(generatorNames, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return genPipeline.Detect(ctx, srcDir)
+}
+
+// GeneratorNames returns the names of all registered generators in alphabetical order
+func GeneratorNames() []string {
+	names := make([]string, 0, len(registeredGenerators))
+	for name := range registeredGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// relPaths returns paths relative to srcDir with forward slashes, paths outside of srcDir are kept as is
+func relPaths(srcDir string, paths ...string) []string {
+	res := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if rel, err := filepath.Rel(srcDir, p); err == nil {
+			p = rel
+		}
+		res = append(res, filepath.ToSlash(p))
+	}
+	return res
+}
@@ -3,66 +3,137 @@ package generate
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
 
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
 	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 )
 
+// AutoGenerators selects every registered generator, only the ones detecting their technology add jobs
+const AutoGenerators = "auto"
+
 var registeredGenerators = map[string][]Generator{}
 
 type WorkflowContext struct {
-	Workflow *dsl.Workflow
-	SrcDir   string
+	Workflow   *dsl.Workflow
+	SrcDir     string
+	OnConflict ConflictStrategy
+	Detections []Detection
+}
+
+// Options configures a Generate run
+type Options struct {
+	WorkflowPath string
+	SrcDir       string
+	// Generators to run, AutoGenerators or no generators selects all of them
+	Generators []string
+	// Exclude lists generators which are not run
+	Exclude    []string
+	OnConflict ConflictStrategy
+	// DryRun generates the workflow without writing it to WorkflowPath
+	DryRun bool
+}
+
+// Output is the advisor result document described by advisor-output.scheme.json.
+type Output struct {
+	Workflow     string      `json:"workflow"`
+	Technologies []string    `json:"technologies"`
+	Generators   []string    `json:"generators"`
+	Detections   []Detection `json:"detections"`
 }
 
 type Generator interface {
-	Generate(ctx context.Context, workflowContext *WorkflowContext) error
+	// Detect returns a detection for every project in srcDir the generator can build
+	Detect(ctx context.Context, srcDir string) ([]Detection, error)
+	// Generate adds the jobs building detected projects to the workflow
+	Generate(ctx context.Context, workflowContext *WorkflowContext, detections []Detection) error
 }
 
-func Generate(ctx context.Context, workflowPath string, src string, generatorNames []string) error {
+func Generate(ctx context.Context, opts Options) (*Output, error) {
+	workflowPath := opts.WorkflowPath
 	exists, err := utils.Stat(workflowPath)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !exists {
-		workflow := baseWorkflow()
-		err := utils.MarshalWorkflowToFile(workflowPath, workflow)
+	workflow := baseWorkflow()
+	if exists {
+		workflow, err = utils.UnmarshalWorkflow(workflowPath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	workflow, err := utils.UnmarshalWorkflow(workflowPath)
+	genPipeline, err := buildPipeline(opts.Generators, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	wContext := &WorkflowContext{
+		Workflow:   workflow,
+		SrcDir:     opts.SrcDir,
+		OnConflict: opts.OnConflict,
+	}
 
+	err = genPipeline.Generate(ctx, wContext)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	genPipeline, err := buildPipeline(generatorNames)
+	b, err := utils.MarshalWorkflow(wContext.Workflow)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	wContext := &WorkflowContext{
-		Workflow: workflow,
-		SrcDir:   src,
+	if !opts.DryRun {
+		err = os.WriteFile(workflowPath, b, 0640)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = genPipeline.Generate(ctx, wContext)
-	if err != nil {
-		return err
+	return newOutput(string(b), wContext.Detections), nil
+}
+
+func newOutput(workflow string, detections []Detection) *Output {
+	out := &Output{
+		Workflow:     workflow,
+		Technologies: []string{},
+		Generators:   []string{},
+		Detections:   detections,
+	}
+	if out.Detections == nil {
+		out.Detections = []Detection{}
 	}
 
-	err = utils.MarshalWorkflowToFile(workflowPath, wContext.Workflow)
-	return err
+	for _, d := range detections {
+		if !slices.Contains(out.Technologies, d.Technology) {
+			out.Technologies = append(out.Technologies, d.Technology)
+		}
+		if !slices.Contains(out.Generators, d.Generator) {
+			out.Generators = append(out.Generators, d.Generator)
+		}
+	}
+
+	return out
 }
 
-func buildPipeline(generatorNames []string) (Generator, error) {
+func buildPipeline(generatorNames []string, exclude []string) (*pipeline, error) {
 	generators := []Generator{}
 
-	for _, name := range generatorNames {
+	for _, name := range exclude {
+		if _, ok := registeredGenerators[name]; !ok {
+			return nil, fmt.Errorf("can not exclude generators with name '%s', no such generator", name)
+		}
+	}
+
+	for _, name := range resolveGeneratorNames(generatorNames) {
+		if slices.Contains(exclude, name) {
+			continue
+		}
+
 		gen, ok := registeredGenerators[name]
 
 		if !ok {
@@ -75,6 +146,27 @@ func buildPipeline(generatorNames []string) (Generator, error) {
 	return &pipeline{tasks: generators}, nil
 }
 
+// resolveGeneratorNames replaces AutoGenerators with the names of all registered generators, no names means auto
+func resolveGeneratorNames(generatorNames []string) []string {
+	if len(generatorNames) == 0 {
+		return GeneratorNames()
+	}
+
+	var names []string
+	for _, name := range generatorNames {
+		if name != AutoGenerators {
+			names = append(names, name)
+			continue
+		}
+		for _, registered := range GeneratorNames() {
+			if !slices.Contains(generatorNames, registered) {
+				names = append(names, registered)
+			}
+		}
+	}
+	return names
+}
+
 func baseWorkflow() *dsl.Workflow {
 	return &dsl.Workflow{
 		APIVersion: utils.CurrentApiVersion,
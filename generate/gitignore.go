@@ -0,0 +1,131 @@
+package generate
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/calculi-corp/workflow-advisor/pkg/utils"
+)
+
+// gitignore matches paths against the patterns of the .gitignore file in the root of the source directory.
+// Nested .gitignore files and the global excludes of git are not read.
+type gitignore struct {
+	root     string
+	patterns []gitignorePattern
+}
+
+type gitignorePattern struct {
+	// segments are the parts of the pattern separated by slashes, ** matches any number of segments
+	segments []string
+	negate   bool
+	dirOnly  bool
+	// anchored patterns are matched against the whole path, others against the name only
+	anchored bool
+}
+
+// readGitignore reads the .gitignore file in root, nil is returned if there is none. A nil *gitignore ignores
+// nothing.
+func readGitignore(root string) (*gitignore, error) {
+	b, err := utils.ReadFileIfExists(filepath.Join(root, ".gitignore"))
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	g := &gitignore{root: root}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p gitignorePattern
+		if p.negate = strings.HasPrefix(line, "!"); p.negate {
+			line = line[1:]
+		}
+		// a backslash escapes a leading ! or #
+		line = strings.TrimPrefix(line, `\`)
+		if p.dirOnly = strings.HasSuffix(line, "/"); p.dirOnly {
+			line = strings.TrimRight(line, "/")
+		}
+		p.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		p.segments = strings.Split(line, "/")
+		g.patterns = append(g.patterns, p)
+	}
+	return g, nil
+}
+
+// withDirs returns a copy of g additionally ignoring dirs, given relative to the root
+func (g *gitignore) withDirs(dirs ...string) *gitignore {
+	if len(dirs) == 0 {
+		return g
+	}
+	res := &gitignore{root: g.root, patterns: slices.Clip(g.patterns)}
+	for _, dir := range dirs {
+		// appended last, so negated patterns of the .gitignore file cannot include them again
+		res.patterns = append(res.patterns, gitignorePattern{
+			segments: strings.Split(dir, "/"),
+			dirOnly:  true,
+			anchored: true,
+		})
+	}
+	return res
+}
+
+// ignored reports whether path, a file or directory below the root, is ignored. As git does not descend into
+// ignored directories, the parents of path are expected not to be ignored.
+func (g *gitignore) ignored(file string, isDir bool) bool {
+	if g == nil {
+		return false
+	}
+	rel, err := filepath.Rel(g.root, file)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	// the last matching pattern decides
+	ignored := false
+	for _, p := range g.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.matches(parts) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+func (p gitignorePattern) matches(parts []string) bool {
+	if !p.anchored {
+		ok, _ := path.Match(p.segments[0], parts[len(parts)-1])
+		return ok
+	}
+	return matchSegments(p.segments, parts)
+}
+
+// matchSegments matches the segments of a path against the segments of a pattern
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchSegments(pattern[1:], parts[1:])
+}
@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitignore(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(`# comment
+*.pyc
+build/
+/dist
+docs/generated
+**/fixtures/*.py
+env*
+!envoy
+\#notes
+`), 0640)
+	require.NoError(t, err)
+
+	ignore, err := readGitignore(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "app/main.pyc", expected: true},
+		{path: "app/main.py", expected: false},
+		{path: "build", isDir: true, expected: true},
+		{path: "app/build", isDir: true, expected: true},
+		{path: "build", isDir: false, expected: false},
+		{path: "dist", isDir: true, expected: true},
+		{path: "app/dist", isDir: true, expected: false},
+		{path: "docs/generated", isDir: true, expected: true},
+		{path: "app/docs/generated", isDir: true, expected: false},
+		{path: "fixtures/data.py", expected: true},
+		{path: "tests/unit/fixtures/data.py", expected: true},
+		{path: "tests/unit/fixtures/data.json", expected: false},
+		{path: "env311", isDir: true, expected: true},
+		{path: "envoy", isDir: true, expected: false},
+		{path: "#notes", expected: true},
+		{path: "comment", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.expected, ignore.ignored(filepath.Join(dir, tt.path), tt.isDir))
+		})
+	}
+}
+
+func TestGitignoreMissing(t *testing.T) {
+	ignore, err := readGitignore(t.TempDir())
+	require.NoError(t, err)
+	require.Nil(t, ignore)
+	require.False(t, ignore.ignored("main.py", false))
+}
+
+func TestGitignoreWithDirs(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.pyc\n!services/\n"), 0640)
+	require.NoError(t, err)
+
+	ignore, err := readGitignore(dir)
+	require.NoError(t, err)
+	nested := ignore.withDirs("services/api")
+
+	require.True(t, nested.ignored(filepath.Join(dir, "services/api"), true))
+	require.False(t, nested.ignored(filepath.Join(dir, "services/api"), false))
+	require.False(t, nested.ignored(filepath.Join(dir, "services/worker"), true))
+	require.True(t, nested.ignored(filepath.Join(dir, "main.pyc"), false))
+	require.False(t, ignore.ignored(filepath.Join(dir, "services/api"), true))
+}
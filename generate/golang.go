@@ -1,84 +1,633 @@
 package generate
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
 	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 )
 
+const (
+	// latestGoVersion is the newest Go release with an official image, used when go.mod does not require a version
+	// or requires a newer one
+	latestGoVersion = "1.26"
+	// golangImage is Debian based as the race detector requires cgo and a C toolchain
+	golangImage = "docker://golang:%s"
+	// golangciLintPackage is installed for configurations in the version 2 format, golangciLintV1Package otherwise.
+	// golangci-lint refuses modules requiring a newer Go than it was built with, so it is built by the Go image of
+	// the module rather than run from its own image.
+	golangciLintPackage   = "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.5.0"
+	golangciLintV1Package = "github.com/golangci/golangci-lint/cmd/golangci-lint@v1.64.8"
+	// goCoverProfile is written by the test step relative to the module directory
+	goCoverProfile = "coverage.out"
+	// goBinDir is the directory binaries of main packages are written to relative to the module directory
+	goBinDir = "bin"
+	// goreleaserImage is pinned as major releases drop deprecated configuration
+	goreleaserImage = "docker://goreleaser/goreleaser:v2.11.0"
+	kanikoAction    = "cloudbees-io/kaniko@v1"
+)
+
+// goreleaserConfigs are the configuration files goreleaser looks up in the project directory
+var goreleaserConfigs = []string{".goreleaser.yaml", ".goreleaser.yml"}
+
+// golangciLintConfigs are the configuration files golangci-lint looks up in the module directory
+var golangciLintConfigs = []string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
+
+// golangciLintV2 matches the version field of a golangci-lint version 2 configuration in any supported format
+var golangciLintV2 = regexp.MustCompile(`^\s*"?version"?\s*[:=]\s*["']?2["']?\s*,?\s*$`)
+
 type golang struct {
 	jobName string
 }
 
+type golangProject struct {
+	// name of the module, or of the directory for workspaces
+	name string
+	// packages are the patterns matching the packages of every module built by the project
+	packages []string
+	// binaries are built from the main packages of the project
+	binaries []goBinary
+	// lintPackage is set when the module has a golangci-lint configuration
+	lintPackage string
+	// lintConfig is the golangci-lint configuration relative to the project directory when it is in a workspace
+	// module, golangci-lint does not find it by itself then
+	lintConfig string
+	// dockerfile is set when the project has a Dockerfile to build an image from
+	dockerfile bool
+	// goreleaser is set when the project is released with goreleaser
+	goreleaser bool
+}
+
+type goBinary struct {
+	name string
+	// pkg is the path of the main package relative to the project directory
+	pkg string
+}
+
 func init() {
 	registerGenerator("go", &golang{
 		jobName: "go-build",
 	})
 }
 
-func (g *golang) Generate(ctx context.Context, workflowContext *WorkflowContext) error {
-	isGolang, err := g.containsSource(workflowContext.SrcDir)
+func (g *golang) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	workspaces, err := findProjects(srcDir, false, hasAnyFile("go.work"))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !isGolang {
-		return nil
+
+	modules, err := findProjects(srcDir, true, hasAnyFile("go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	var detections []Detection
+	// modules used by a workspace are built by the workspace job
+	usedModules := map[string]bool{}
+	for _, workspace := range workspaces {
+		use, err := goWorkModules(filepath.Join(srcDir, workspace, "go.work"))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range use {
+			usedModules[path.Join(workspace, m)] = true
+		}
+
+		d, err := g.detectProject(srcDir, workspace, "go.work", use)
+		if err != nil {
+			return nil, err
+		}
+		detections = append(detections, d)
 	}
 
-	workflow := workflowContext.Workflow
-	return g.addJobIfNotExists(workflow)
+	for _, module := range modules {
+		if usedModules[module] {
+			continue
+		}
+
+		d, err := g.detectProject(srcDir, module, "go.mod", []string{rootProject})
+		if err != nil {
+			return nil, err
+		}
+		detections = append(detections, d)
+	}
+
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Project < detections[j].Project
+	})
+	return detections, nil
 }
 
-func (g *golang) containsSource(srcDir string) (bool, error) {
-	for _, f := range []string{"go.mod", "go.sum"} {
-		exists, err := utils.Stat(filepath.Join(srcDir, f))
+// detectProject returns the detection of a project built from the directory containing manifest, either go.mod or
+// go.work, modules are the directories of the modules built relative to the project
+func (g *golang) detectProject(srcDir, project, manifest string, modules []string) (Detection, error) {
+	projectDir := filepath.Join(srcDir, project)
+	evidence := []string{filepath.Join(projectDir, manifest)}
+	versionFiles := []string{filepath.Join(projectDir, manifest)}
+	details := golangProject{
+		name: filepath.Base(projectDir),
+	}
+	if manifest == "go.mod" {
+		name, err := goModuleName(filepath.Join(projectDir, manifest))
 		if err != nil {
-			return false, err
+			return Detection{}, err
 		}
-		if !exists {
-			return false, nil
+		details.name = name
+	}
+
+	for _, m := range modules {
+		moduleDir := filepath.Join(projectDir, m)
+		if manifest == "go.work" {
+			goMod := filepath.Join(moduleDir, "go.mod")
+			evidence = append(evidence, goMod)
+			versionFiles = append(versionFiles, goMod)
+		}
+
+		goSum := filepath.Join(moduleDir, "go.sum")
+		exists, err := utils.Stat(goSum)
+		if err != nil {
+			return Detection{}, err
+		}
+		if exists {
+			evidence = append(evidence, goSum)
+		}
+
+		details.packages = append(details.packages, goPackages(m))
+
+		binaries, err := goMainPackages(moduleDir)
+		if err != nil {
+			return Detection{}, err
+		}
+		for _, b := range binaries {
+			b.pkg = goPackagePath(path.Join(m, b.pkg))
+			details.binaries = append(details.binaries, b)
+		}
+	}
+
+	version, err := goVersion(versionFiles...)
+	if err != nil {
+		return Detection{}, err
+	}
+
+	d := Detection{
+		Generator:      "go",
+		Technology:     "Go",
+		Project:        project,
+		Version:        version,
+		BuildTool:      "go",
+		PackageManager: "go modules",
+		Evidence:       relPaths(srcDir, evidence...),
+		Confidence:     confidenceHigh,
+	}
+	if manifest == "go.work" {
+		d.Modules = modules
+	}
+
+	// a workspace is linted with the configuration of its root, or else the first one found in its modules
+	lintDirs := []string{rootProject}
+	if manifest == "go.work" {
+		lintDirs = append(lintDirs, modules...)
+	}
+	for _, m := range lintDirs {
+		lintConfig, lintPackage, err := golangciLint(filepath.Join(projectDir, m))
+		if err != nil {
+			return Detection{}, err
+		}
+		if lintConfig == "" {
+			continue
+		}
+		details.lintPackage = lintPackage
+		if m != rootProject {
+			details.lintConfig = path.Join(m, filepath.Base(lintConfig))
+		}
+		d.Evidence = append(d.Evidence, relPaths(srcDir, lintConfig)...)
+		break
+	}
+
+	dockerfile := filepath.Join(projectDir, "Dockerfile")
+	details.dockerfile, err = utils.Stat(dockerfile)
+	if err != nil {
+		return Detection{}, err
+	}
+	if details.dockerfile {
+		d.Evidence = append(d.Evidence, relPaths(srcDir, dockerfile)...)
+	}
+
+	for _, name := range goreleaserConfigs {
+		config := filepath.Join(projectDir, name)
+		exists, err := utils.Stat(config)
+		if err != nil {
+			return Detection{}, err
+		}
+		if exists {
+			details.goreleaser = true
+			d.Evidence = append(d.Evidence, relPaths(srcDir, config)...)
+			break
 		}
 	}
-	return true, nil
+	d.details = details
+
+	return d, nil
 }
 
-func (g *golang) addJobIfNotExists(wf *dsl.Workflow) error {
-	if wf.Jobs == nil {
-		wf.Jobs = make(map[string]dsl.Job)
+// goPackages returns the package pattern matching every package of the module in dir
+func goPackages(dir string) string {
+	if dir == rootProject {
+		return "./..."
 	}
+	return goPackagePath(dir) + "/..."
+}
 
-	if _, ok := wf.Jobs[g.jobName]; ok {
-		return fmt.Errorf("error adding job: job %s already exists", g.jobName)
+// goPackagePath returns the relative package path of dir as accepted by the go command
+func goPackagePath(dir string) string {
+	if dir == rootProject || strings.HasPrefix(dir, "../") {
+		return dir
 	}
+	return "./" + dir
+}
 
-	wf.Jobs[g.jobName] = dsl.Job{
-		Steps: []dsl.Step{
-			{
-				Name: "checkout",
-				Uses: "cloudbees-io/checkout@v1",
-			},
-			{
-				Name: "test",
-				Uses: "docker://golang:1.22-alpine3.19",
-				Run:  "go test -cover ./...",
+// goMainPackages returns a binary for every main package of the module in moduleDir, nested modules are skipped.
+// Binaries are named after their directory, or the last element of the module path for the module directory.
+func goMainPackages(moduleDir string) ([]goBinary, error) {
+	var binaries []goBinary
+	err := filepath.WalkDir(moduleDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if p != moduleDir {
+			if isIgnoredDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			if nested, err := utils.Stat(filepath.Join(p, "go.mod")); err != nil || nested {
+				if err == nil {
+					err = filepath.SkipDir
+				}
+				return err
+			}
+		}
+
+		isMain, err := isGoMainPackage(p)
+		if err != nil || !isMain {
+			return err
+		}
+
+		rel, err := filepath.Rel(moduleDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		name := path.Base(rel)
+		if rel == rootProject {
+			name, err = goModuleName(filepath.Join(moduleDir, "go.mod"))
+			if err != nil {
+				return err
+			}
+		}
+		binaries = append(binaries, goBinary{name: name, pkg: rel})
+		return nil
+	})
+	return binaries, err
+}
+
+// isGoMainPackage reports whether dir contains a main package, test files and files excluded with the ignore
+// build tag, as usual for generators, do not count
+func isGoMainPackage(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			// ignore files which are not valid go
+			continue
+		}
+		if f.Name.Name != "main" || isIgnoredGoFile(f) {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func isIgnoredGoFile(f *ast.File) bool {
+	for _, group := range f.Comments {
+		if group.Pos() > f.Package {
+			break
+		}
+		for _, c := range group.List {
+			if constraint, ok := strings.CutPrefix(c.Text, "//go:build "); ok && strings.TrimSpace(constraint) == "ignore" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goModuleName returns the last element of the module path declared in goMod ignoring a major version suffix
+func goModuleName(goMod string) (string, error) {
+	var modulePath string
+	err := goDirectives(goMod, func(directive string, args []string) {
+		if directive == "module" && len(args) == 1 {
+			modulePath = strings.Trim(args[0], `"`)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	name := path.Base(modulePath)
+	if isMajorVersion(name) {
+		name = path.Base(path.Dir(modulePath))
+	}
+	if name == "." || name == "/" {
+		name = path.Base(filepath.ToSlash(filepath.Dir(goMod)))
+	}
+	return name, nil
+}
+
+func isMajorVersion(s string) bool {
+	v, ok := strings.CutPrefix(s, "v")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(v)
+	return err == nil
+}
+
+func (g *golang) Generate(ctx context.Context, workflowContext *WorkflowContext, detections []Detection) error {
+	for _, d := range detections {
+		err := g.addJob(workflowContext, d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *golang) addJob(workflowContext *WorkflowContext, d Detection) error {
+	image := fmt.Sprintf(golangImage, d.Version)
+	details, _ := d.details.(golangProject)
+	packages := strings.Join(details.packages, " ")
+
+	steps := []dsl.Step{
+		{
+			Name: "checkout",
+			Uses: "cloudbees-io/checkout@v1",
+		},
+		{
+			Name: "vet",
+			Uses: image,
+			Run:  "go vet " + packages,
+		},
+	}
+
+	if details.lintPackage != "" {
+		lint := "golangci-lint run " + packages
+		if details.lintConfig != "" {
+			lint = fmt.Sprintf("golangci-lint run --config %s %s", details.lintConfig, packages)
+		}
+		steps = append(steps, dsl.Step{
+			Name: "lint",
+			Uses: image,
+			Run:  fmt.Sprintf("go install %s\n%s", details.lintPackage, lint),
+		})
+	}
+
+	steps = append(steps,
+		dsl.Step{
+			Name: "test",
+			Uses: image,
+			Run:  fmt.Sprintf("go test -race -covermode=atomic -coverprofile=%s %s", goCoverProfile, packages),
+		},
+		goBuildStep(image, packages, details.binaries),
+		dsl.Step{
+			ID:   "coverage",
+			Name: "coverage report",
+			Uses: image,
+			Run:  fmt.Sprintf(`go tool cover -func=%s > "$CLOUDBEES_OUTPUTS/report"`, goCoverProfile),
+		},
+		dsl.Step{
+			Name: "publish coverage",
+			Uses: "cloudbees-io/publish-evidence-item@v1",
+			With: map[string]string{
+				"content": "${{ steps.coverage.outputs.report }}",
 			},
-			{
-				Name: "build",
-				Uses: "docker://golang:1.22-alpine3.19",
-				Run:  "go build ./...",
+		},
+	)
+
+	if details.goreleaser {
+		steps = append(steps, dsl.Step{
+			Name: "release snapshot",
+			Uses: goreleaserImage,
+			Run:  "goreleaser release --snapshot --clean",
+		})
+	}
+
+	if details.dockerfile {
+		steps = append(steps, dsl.Step{
+			Name: "build image",
+			Uses: kanikoAction,
+			With: map[string]string{
+				"dockerfile":  path.Join(d.Project, "Dockerfile"),
+				"context":     d.Project,
+				"destination": fmt.Sprintf("${{ vars.DOCKER_REGISTRY }}/%s:${{ cloudbees.scm.sha }}", goImageName(details)),
 			},
-			{
-				Name: "scan",
-				Uses: "cloudbees-io/sonarqube-bundled-sast-scan-code@v2",
-				With: map[string]string{
-					"language": "LANGUAGE_GO",
-				},
+		})
+	}
+
+	steps = append(steps,
+		dsl.Step{
+			Name: "scan",
+			Uses: "cloudbees-io/sonarqube-bundled-sast-scan-code@v2",
+			With: map[string]string{
+				"language": "LANGUAGE_GO",
 			},
 		},
+	)
+
+	return workflowContext.addJob(projectJobName(g.jobName, d.Project), dsl.Job{
+		Steps: inProject(d.Project, steps),
+	})
+}
+
+// goBuildStep builds every binary with cgo disabled, or compiles all packages if the project has no main package
+func goBuildStep(image, packages string, binaries []goBinary) dsl.Step {
+	if len(binaries) == 0 {
+		return dsl.Step{
+			Name: "build",
+			Uses: image,
+			Run:  "go build " + packages,
+		}
 	}
 
-	return nil
+	cmds := make([]string, 0, len(binaries))
+	for _, b := range binaries {
+		cmds = append(cmds, fmt.Sprintf("CGO_ENABLED=0 go build -o %s %s", path.Join(goBinDir, b.name), b.pkg))
+	}
+	return dsl.Step{
+		Name: "build",
+		Uses: image,
+		Run:  strings.Join(cmds, "\n"),
+	}
+}
+
+// goImageName names the container image after the only binary of the project, or the project otherwise
+func goImageName(details golangProject) string {
+	if len(details.binaries) == 1 {
+		return details.binaries[0].name
+	}
+	return details.name
+}
+
+// golangciLint returns the golangci-lint configuration of the module in dir and the package matching its format,
+// or empty strings if the module is not configured for golangci-lint
+func golangciLint(dir string) (string, string, error) {
+	for _, name := range golangciLintConfigs {
+		config := filepath.Join(dir, name)
+		exists, err := utils.Stat(config)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			continue
+		}
+
+		isV2, err := fileContains(config, golangciLintV2.MatchString)
+		if err != nil {
+			return "", "", err
+		}
+		if isV2 {
+			return config, golangciLintPackage, nil
+		}
+		return config, golangciLintV1Package, nil
+	}
+	return "", "", nil
+}
+
+// goVersion returns the major.minor Go release to build with, based on the go and toolchain directives of the
+// go.mod or go.work files. The newest of all is used, latestGoVersion when none is set, can not be parsed or is not
+// released yet.
+func goVersion(files ...string) (string, error) {
+	var major, minor int
+	for _, file := range files {
+		err := goDirectives(file, func(directive string, args []string) {
+			if len(args) != 1 {
+				return
+			}
+
+			var v string
+			switch directive {
+			case "go":
+				v = args[0]
+			case "toolchain":
+				v = strings.TrimPrefix(args[0], "go")
+			default:
+				return
+			}
+
+			vMajor, vMinor, ok := parseGoVersion(v)
+			if ok && (vMajor > major || (vMajor == major && vMinor > minor)) {
+				major, minor = vMajor, vMinor
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	latestMajor, latestMinor, _ := parseGoVersion(latestGoVersion)
+	if major == 0 || major > latestMajor || (major == latestMajor && minor > latestMinor) {
+		return latestGoVersion, nil
+	}
+	return fmt.Sprintf("%d.%d", major, minor), nil
+}
+
+// goWorkModules returns the module directories of the use directives in goWork relative to its directory
+func goWorkModules(goWork string) ([]string, error) {
+	var modules []string
+	err := goDirectives(goWork, func(directive string, args []string) {
+		if directive == "use" && len(args) == 1 {
+			modules = append(modules, path.Clean(strings.Trim(args[0], `"`+"`")))
+		}
+	})
+	return modules, err
+}
+
+// goDirectives calls fn for every directive of a go.mod or go.work file, directives of a block like
+// use ( ./a ./b ) are reported one by one
+func goDirectives(file string, fn func(directive string, args []string)) error {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	block := ""
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case block != "" && fields[0] == ")":
+			block = ""
+		case block != "":
+			fn(block, fields)
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+		default:
+			fn(fields[0], fields[1:])
+		}
+	}
+	return s.Err()
+}
+
+// parseGoVersion returns major and minor of a Go version like 1.22, 1.22.3 or 1.23rc1
+func parseGoVersion(v string) (int, int, bool) {
+	majorStr, rest, ok := strings.Cut(v, ".")
+	if !ok {
+		return 0, 0, false
+	}
+	minorStr := rest
+	if i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minorStr = rest[:i]
+	}
+
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(minorStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
 }
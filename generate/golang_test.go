@@ -0,0 +1,124 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		goMod    string
+		expected string
+	}{
+		{
+			name:     "go directive",
+			goMod:    "module example.com/m\n\ngo 1.21\n",
+			expected: "1.21",
+		},
+		{
+			name:     "go directive with patch",
+			goMod:    "module example.com/m\n\ngo 1.22.3\n",
+			expected: "1.22",
+		},
+		{
+			name:     "toolchain newer than go directive",
+			goMod:    "module example.com/m\n\ngo 1.21\n\ntoolchain go1.24.1\n",
+			expected: "1.24",
+		},
+		{
+			name:     "release candidate",
+			goMod:    "module example.com/m\n\ngo 1.25rc1 // comment\n",
+			expected: "1.25",
+		},
+		{
+			name:     "not released yet",
+			goMod:    "module example.com/m\n\ngo 1.99\n",
+			expected: latestGoVersion,
+		},
+		{
+			name:     "no go directive",
+			goMod:    "module example.com/m\n",
+			expected: latestGoVersion,
+		},
+		{
+			name:     "invalid go directive",
+			goMod:    "module example.com/m\n\ngo latest\n",
+			expected: latestGoVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goMod := filepath.Join(t.TempDir(), "go.mod")
+			err := os.WriteFile(goMod, []byte(tt.goMod), 0640)
+			require.NoError(t, err)
+
+			version, err := goVersion(goMod)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestGolangciLint(t *testing.T) {
+	tests := []struct {
+		name            string
+		file            string
+		content         string
+		expectedPackage string
+	}{
+		{
+			name:            "v2 yaml",
+			file:            ".golangci.yml",
+			content:         "version: \"2\"\nlinters:\n  default: standard\n",
+			expectedPackage: golangciLintPackage,
+		},
+		{
+			name:            "v2 toml",
+			file:            ".golangci.toml",
+			content:         "version = '2'\n",
+			expectedPackage: golangciLintPackage,
+		},
+		{
+			name:            "v1 yaml",
+			file:            ".golangci.yaml",
+			content:         "linters:\n  enable:\n    - gofmt\n",
+			expectedPackage: golangciLintV1Package,
+		},
+		{
+			name: "not configured",
+			file: "README.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.content), 0640)
+			require.NoError(t, err)
+
+			config, pkg, err := golangciLint(dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedPackage, pkg)
+			if tt.expectedPackage != "" {
+				require.Equal(t, filepath.Join(dir, tt.file), config)
+			} else {
+				require.Empty(t, config)
+			}
+		})
+	}
+}
+
+func TestGoWorkModules(t *testing.T) {
+	goWork := filepath.Join(t.TempDir(), "go.work")
+	err := os.WriteFile(goWork, []byte("go 1.24\n\nuse ./tools\n\nuse (\n\t.\n\t./api // service\n\t\"./lib\"\n)\n\nreplace example.com/x => ../x\n"), 0640)
+	require.NoError(t, err)
+
+	modules, err := goWorkModules(goWork)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tools", ".", "api", "lib"}, modules)
+}
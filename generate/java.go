@@ -2,143 +2,577 @@ package generate
 
 import (
 	"context"
+	"encoding/xml"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
 	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 )
 
 type java struct {
-	jobName string
+	// jobNames are the job names by technology
+	jobNames map[string]string
 }
 
 type javaBuildStep struct {
-	files []string
-	steps []dsl.Step
+	files     []string
+	buildTool string
+	image     string
+	command   string
+}
+
+// javaProject holds what was detected about a single JVM build
+type javaProject struct {
+	buildStep *javaBuildStep
+	jdk       int
+	// technology is Java, Kotlin, Scala or Android
+	technology string
+	// kotlin is set if the sources are written in Kotlin, Android projects can be written in either language
+	kotlin bool
 }
 
 func init() {
 	registerGenerator("java", &java{
-		jobName: "java-build",
+		jobNames: map[string]string{
+			"Java":    "java-build",
+			"Kotlin":  "kotlin-build",
+			"Scala":   "scala-build",
+			"Android": "android-build",
+		},
 	})
 }
 
 const (
-	defaultMavenImage  = "maven:3.9-eclipse-temurin-21-alpine"
-	defaultGradleImage = "gradle:8.6-jdk21-alpine"
+	mavenImage  = "docker://maven:3.9-eclipse-temurin-%d"
+	gradleImage = "docker://gradle:jdk%d"
+	// sbtImage tags pin the JDK, sbt and Scala versions, sbt downloads the versions configured by the project
+	sbtImage = "docker://sbtscala/scala-sbt:eclipse-temurin-21.0.8_9_1.11.6_3.7.3"
+	// androidImage provides the Android SDK and a JDK supported by the Android Gradle plugin
+	androidImage = "docker://ghcr.io/cirruslabs/android-sdk:35"
+	// defaultJDK is used when the build does not tell which Java version it needs
+	defaultJDK = 21
 )
 
-// dsl steps based on repo contents
+// temurinJDKs are the LTS releases Temurin based images are published for
+var temurinJDKs = []int{8, 11, 17, 21, 25}
+
+// build tools based on repo contents, wrappers are preferred over a build tool installed in the image
 var javaBuildSteps = []javaBuildStep{
 	{
-		files: []string{"mvnw"},
-		steps: []dsl.Step{
-			{
-				Name: "mvn install",
-				Uses: "docker://" + defaultMavenImage,
-				Run:  "./mvnw install",
-			},
-		},
+		files:     []string{"mvnw"},
+		buildTool: "maven",
+		image:     mavenImage,
+		// wrappers are run with sh as they are often committed without the executable bit
+		command: "sh mvnw",
 	},
 	{
-		files: []string{"pom.xml"},
-		steps: []dsl.Step{
-			{
-				Name: "mvn install",
-				Uses: "docker://" + defaultMavenImage,
-				Run:  "mvn install",
-			},
-		},
+		files:     []string{"pom.xml"},
+		buildTool: "maven",
+		image:     mavenImage,
+		command:   "mvn",
 	},
 	{
-		files: []string{"gradlew"},
-		steps: []dsl.Step{
-			{
-				Name: "gradle build",
-				Uses: "docker://" + defaultGradleImage,
-				Run:  "./gradlew build",
-			},
-			{
-				Name: "gradle test",
-				Uses: "docker://" + defaultGradleImage,
-				Run:  "./gradlew test",
-			},
-		},
+		files:     []string{"gradlew"},
+		buildTool: "gradle",
+		image:     gradleImage,
+		command:   "sh gradlew",
 	},
 	{
-		files: []string{"build.gradle", "build.gradle.kts"},
-		steps: []dsl.Step{
-			{
-				Name: "gradle build",
-				Uses: "docker://" + defaultGradleImage,
-				Run:  "gradle build",
-			},
-			{
-				Name: "gradle test",
-				Uses: "docker://" + defaultGradleImage,
-				Run:  "gradle test",
-			},
-		},
+		files:     []string{"build.gradle", "build.gradle.kts"},
+		buildTool: "gradle",
+		image:     gradleImage,
+		command:   "gradle",
+	},
+	{
+		files:     []string{"build.sbt"},
+		buildTool: "sbt",
+		image:     sbtImage,
+		command:   "sbt",
 	},
 }
 
-func (j *java) Generate(ctx context.Context, workflowContext *WorkflowContext) error {
-	dslSteps, err := j.dslSteps(workflowContext.SrcDir)
+// androidPluginRegexp matches the Android Gradle plugins applied to application and library projects
+var androidPluginRegexp = regexp.MustCompile(`com\.android\.(?:application|library)`)
+
+// kotlinPluginRegexp matches the Kotlin Gradle plugin in either DSL and the Kotlin Maven plugin
+var kotlinPluginRegexp = regexp.MustCompile(`kotlin\(\s*"(?:jvm|android|multiplatform)"\s*\)|org\.jetbrains\.kotlin|kotlin-android|kotlin-maven-plugin`)
+
+var (
+	// gradleIncludeRegexp matches include statements in settings.gradle(.kts), projects are captured as a list
+	gradleIncludeRegexp = regexp.MustCompile(`(?m)^\s*include\s*\(?((?:\s*["'][^"']+["']\s*,?)+)\s*\)?`)
+	// gradleIncludeBuildRegexp matches composite builds included in settings.gradle(.kts)
+	gradleIncludeBuildRegexp = regexp.MustCompile(`(?m)^\s*includeBuild\s*\(?\s*["']([^"']+)["']`)
+	// quotedRegexp matches a single or double quoted string
+	quotedRegexp = regexp.MustCompile(`["']([^"']+)["']`)
+	// gradleToolchainRegexp matches java.toolchain.languageVersion and the kotlin jvmToolchain shorthand
+	gradleToolchainRegexp = regexp.MustCompile(`(?:JavaLanguageVersion\.of|jvmToolchain)\(\s*["']?(\d+)["']?\s*\)`)
+	// gradleCompatibilityRegexp matches sourceCompatibility and targetCompatibility assignments
+	gradleCompatibilityRegexp = regexp.MustCompile(`(?:source|target)Compatibility\s*=?\s*(?:JavaVersion\.VERSION_(\d+(?:_\d+)?)|["']?(\d+(?:\.\d+)?)["']?)`)
+)
+
+func (j *java) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	// builds are searched in nested directories as well, directories belonging to a build found before are skipped
+	projects, err := findProjects(srcDir, true, hasAnyFile(javaBuildFiles()...))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(dslSteps) == 0 {
-		return nil
-	}
+	var detections []Detection
+	var covered []string
+	for _, project := range projects {
+		if slices.ContainsFunc(covered, func(dir string) bool {
+			return project == dir || strings.HasPrefix(project, dir+"/")
+		}) {
+			continue
+		}
 
-	return j.addJobIfNotExists(workflowContext.Workflow, dslSteps)
-}
+		projectDir := filepath.Join(srcDir, project)
+		buildStep, evidence, err := j.findBuildStep(projectDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if buildStep == nil {
+			continue
+		}
+
+		modules, parts, err := javaModules(projectDir, buildStep.buildTool)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range append(modules, parts...) {
+			covered = append(covered, path.Join(project, dir))
+		}
+
+		jdk, err := javaVersion(projectDir, buildStep.buildTool)
+		if err != nil {
+			return nil, err
+		}
+
+		technology, kotlin, err := jvmTechnology(projectDir, buildStep.buildTool, modules)
+		if err != nil {
+			return nil, err
+		}
 
-func (j *java) addJobIfNotExists(workflow *dsl.Workflow, steps []dsl.Step) error {
-	if workflow.Jobs == nil {
-		workflow.Jobs = make(map[string]dsl.Job)
+		detections = append(detections, Detection{
+			Generator:  "java",
+			Technology: technology,
+			Project:    project,
+			Version:    strconv.Itoa(jdk),
+			BuildTool:  buildStep.buildTool,
+			Modules:    modules,
+			Evidence:   relPaths(srcDir, evidence),
+			Confidence: confidenceHigh,
+			details: javaProject{
+				buildStep:  buildStep,
+				jdk:        jdk,
+				technology: technology,
+				kotlin:     kotlin,
+			},
+		})
 	}
 
-	_, ok := workflow.Jobs[j.jobName]
+	return detections, nil
+}
 
-	if ok {
-		return fmt.Errorf("error adding job %s, it already exists, java generator is not compatible with workflow provided", j.jobName)
+func (j *java) Generate(ctx context.Context, workflowContext *WorkflowContext, detections []Detection) error {
+	for _, d := range detections {
+		p, _ := d.details.(javaProject)
+		err := j.addJob(workflowContext, d.Project, p)
+		if err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+func (j *java) addJob(workflowContext *WorkflowContext, project string, p javaProject) error {
 	wfSteps := []dsl.Step{
 		{
 			Name: "checkout",
 			Uses: "cloudbees-io/checkout@v1",
 		},
 	}
-	wfSteps = append(wfSteps, steps...)
+	wfSteps = append(wfSteps, p.lifecycle()...)
 	wfSteps = append(wfSteps, dsl.Step{
 		Name: "scan",
 		Uses: "cloudbees-io/sonarqube-bundled-sast-scan-code@v2",
 		With: map[string]string{
-			"language": "JAVA",
+			"language": p.scanLanguage(),
 		},
 	})
-	workflow.Jobs[j.jobName] = dsl.Job{
-		Steps: wfSteps,
+	return workflowContext.addJob(projectJobName(j.jobNames[p.technology], project), dsl.Job{
+		Steps: inProject(project, wfSteps),
+	})
+}
+
+// lifecycle returns the steps compiling, testing and packaging the build in a single pass, non-interactive and
+// without the build daemon as every step runs in a fresh container
+func (p javaProject) lifecycle() []dsl.Step {
+	b := p.buildStep
+	switch {
+	case p.technology == "Android":
+		// only the debug variant is built, release builds require signing configuration
+		return []dsl.Step{
+			{
+				Name: "gradle lint",
+				Uses: androidImage,
+				Run:  b.command + " --no-daemon --console=plain lintDebug",
+			},
+			{
+				Name: "gradle test",
+				Uses: androidImage,
+				Run:  b.command + " --no-daemon --console=plain testDebugUnitTest",
+			},
+			{
+				Name: "gradle assemble",
+				Uses: androidImage,
+				Run:  b.command + " --no-daemon --console=plain assembleDebug",
+			},
+		}
+	case b.buildTool == "sbt":
+		return []dsl.Step{
+			{
+				Name: "sbt test",
+				Uses: b.image,
+				Run:  b.command + " -batch test",
+			},
+		}
+	case b.buildTool == "gradle":
+		image := fmt.Sprintf(b.image, p.jdk)
+		return []dsl.Step{
+			{
+				Name: "gradle check",
+				Uses: image,
+				Run:  b.command + " --no-daemon --console=plain check",
+			},
+			{
+				Name: "gradle assemble",
+				Uses: image,
+				Run:  b.command + " --no-daemon --console=plain assemble",
+			},
+		}
 	}
 
-	return nil
+	return []dsl.Step{
+		{
+			Name: "mvn verify",
+			Uses: fmt.Sprintf(b.image, p.jdk),
+			Run:  b.command + " --batch-mode --no-transfer-progress verify",
+		},
+	}
+}
+
+// scanLanguage returns the language of the project sources passed to the scan
+func (p javaProject) scanLanguage() string {
+	switch {
+	case p.technology == "Scala":
+		return "LANGUAGE_SCALA"
+	case p.kotlin:
+		return "LANGUAGE_KOTLIN"
+	default:
+		return "JAVA"
+	}
+}
+
+// jvmTechnology returns the technology of the build in dir, Android if one of its projects applies the Android
+// plugin, Scala for sbt builds and Kotlin if the Kotlin plugin or Kotlin sources are found. kotlin is set for
+// Kotlin and Android projects written in Kotlin.
+func jvmTechnology(dir, buildTool string, modules []string) (technology string, kotlin bool, err error) {
+	if buildTool == "sbt" {
+		return "Scala", false, nil
+	}
+
+	var buildFiles []string
+	for _, module := range append([]string{"."}, modules...) {
+		for _, f := range []string{"pom.xml", "build.gradle", "build.gradle.kts"} {
+			buildFiles = append(buildFiles, filepath.Join(dir, module, f))
+		}
+	}
+
+	android := false
+	for _, file := range buildFiles {
+		b, err := utils.ReadFileIfExists(file)
+		if err != nil {
+			return "", false, err
+		}
+		android = android || (buildTool == "gradle" && androidPluginRegexp.Match(b))
+		kotlin = kotlin || kotlinPluginRegexp.Match(b)
+	}
+	if !kotlin {
+		kotlin, err = hasKotlinSources(dir)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	switch {
+	case android:
+		return "Android", kotlin, nil
+	case kotlin:
+		return "Kotlin", true, nil
+	default:
+		return "Java", false, nil
+	}
+}
+
+// hasKotlinSources returns true if there is any Kotlin source file below dir
+func hasKotlinSources(dir string) (bool, error) {
+	found := false
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (isIgnoredDir(d.Name()) || d.Name() == "build" || d.Name() == "target") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".kt" {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, err
 }
-func (j *java) dslSteps(srcDir string) ([]dsl.Step, error) {
-	var dslSteps []dsl.Step
+
+// javaBuildFiles returns every build file known to javaBuildSteps
+func javaBuildFiles() []string {
+	var files []string
+	for _, javaBuildStep := range javaBuildSteps {
+		files = append(files, javaBuildStep.files...)
+	}
+	return files
+}
+
+// findBuildStep returns the build step for the first build file found in srcDir and the path of that file
+func (j *java) findBuildStep(srcDir string) (*javaBuildStep, string, error) {
 	var err error
 	var exists bool
-	for _, javaBuildStep := range javaBuildSteps {
+	for i, javaBuildStep := range javaBuildSteps {
 		for _, f := range javaBuildStep.files {
-			if exists, err = utils.Stat(filepath.Join(srcDir, f)); exists && err == nil {
-				dslSteps = append(dslSteps, javaBuildStep.steps...)
-				return dslSteps, nil
+			path := filepath.Join(srcDir, f)
+			if exists, err = utils.Stat(path); exists && err == nil {
+				return &javaBuildSteps[i], path, nil
+			}
+		}
+	}
+
+	return nil, "", err
+}
+
+// javaVersion returns the Temurin JDK matching the Java version the build in dir requires, the oldest LTS release
+// supporting it, defaultJDK if the build does not tell
+func javaVersion(dir, buildTool string) (int, error) {
+	var version int
+	var err error
+	switch buildTool {
+	case "maven":
+		version, err = mavenJavaVersion(dir)
+	case "gradle":
+		version, err = gradleJavaVersion(filepath.Join(dir, "build.gradle"), filepath.Join(dir, "build.gradle.kts"))
+	}
+	if err != nil || version == 0 {
+		return defaultJDK, err
+	}
+
+	for _, jdk := range temurinJDKs {
+		if jdk >= version {
+			return jdk, nil
+		}
+	}
+	return temurinJDKs[len(temurinJDKs)-1], nil
+}
+
+// pomXML contains the parts of pom.xml used to find the modules and Java version
+type pomXML struct {
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Modules []string `xml:"modules>module"`
+	Plugins []struct {
+		ArtifactID    string `xml:"artifactId"`
+		Configuration struct {
+			Release string `xml:"release"`
+			Source  string `xml:"source"`
+			Target  string `xml:"target"`
+		} `xml:"configuration"`
+	} `xml:"build>plugins>plugin"`
+}
+
+// javaModules returns the directories of the modules of the build in dir relative to dir, and other directories
+// which are part of the build without being modules, e.g. gradle buildSrc
+func javaModules(dir, buildTool string) ([]string, []string, error) {
+	if buildTool == "maven" {
+		modules, err := mavenModules(dir, "")
+		return modules, nil, err
+	}
+	return gradleProjects(dir)
+}
+
+// readPOM parses the pom.xml in dir, a missing or invalid pom.xml results in an empty pomXML
+func readPOM(dir string) (pomXML, error) {
+	var pom pomXML
+	b, err := os.ReadFile(filepath.Join(dir, "pom.xml"))
+	if os.IsNotExist(err) {
+		return pom, nil
+	}
+	if err != nil {
+		return pom, err
+	}
+	if err := xml.Unmarshal(b, &pom); err != nil {
+		// ignore pom.xml in unknown format
+		return pomXML{}, nil
+	}
+	return pom, nil
+}
+
+// mavenModules returns the modules of the pom.xml in root/dir and of their modules, relative to root
+func mavenModules(root, dir string) ([]string, error) {
+	pom, err := readPOM(filepath.Join(root, dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []string
+	for _, m := range pom.Modules {
+		m = filepath.ToSlash(strings.TrimSpace(m))
+		// modules may reference a pom file instead of a directory
+		if strings.HasSuffix(m, ".xml") {
+			m = path.Dir(m)
+		}
+		module := path.Join(dir, m)
+		if module == "." || strings.HasPrefix(module, "../") {
+			continue
+		}
+		modules = append(modules, module)
+
+		nested, err := mavenModules(root, module)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, nested...)
+	}
+	return modules, nil
+}
+
+// gradleProjects returns the projects included by settings.gradle(.kts) in dir, and buildSrc and included builds
+func gradleProjects(dir string) ([]string, []string, error) {
+	var projects []string
+	parts := []string{"buildSrc"}
+	for _, f := range []string{"settings.gradle", "settings.gradle.kts"} {
+		b, err := utils.ReadFileIfExists(filepath.Join(dir, f))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, include := range gradleIncludeRegexp.FindAllSubmatch(b, -1) {
+			for _, m := range quotedRegexp.FindAllSubmatch(include[1], -1) {
+				project := strings.ReplaceAll(strings.TrimPrefix(string(m[1]), ":"), ":", "/")
+				if project != "" && !slices.Contains(projects, project) {
+					projects = append(projects, project)
+				}
+			}
+		}
+		for _, m := range gradleIncludeBuildRegexp.FindAllSubmatch(b, -1) {
+			if included := path.Clean(string(m[1])); !strings.HasPrefix(included, "..") {
+				parts = append(parts, included)
+			}
+		}
+	}
+	return projects, parts, nil
+}
+
+// mavenJavaVersion returns the Java version configured by the maven compiler properties or plugin of the pom.xml in dir,
+// 0 if there is none
+func mavenJavaVersion(dir string) (int, error) {
+	pom, err := readPOM(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	properties := map[string]string{}
+	for _, e := range pom.Properties.Entries {
+		properties[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	resolve := func(v string) string {
+		v = strings.TrimSpace(v)
+		if name, ok := strings.CutPrefix(v, "${"); ok {
+			return properties[strings.TrimSuffix(name, "}")]
+		}
+		return v
+	}
+
+	candidates := []string{
+		properties["maven.compiler.release"],
+		properties["java.version"],
+	}
+	for _, plugin := range pom.Plugins {
+		if plugin.ArtifactID == "maven-compiler-plugin" {
+			candidates = append(candidates, plugin.Configuration.Release, plugin.Configuration.Source, plugin.Configuration.Target)
+		}
+	}
+	candidates = append(candidates, properties["maven.compiler.source"], properties["maven.compiler.target"])
+
+	for _, c := range candidates {
+		if version, ok := parseJavaVersion(resolve(c)); ok {
+			return version, nil
+		}
+	}
+	return 0, nil
+}
+
+// gradleJavaVersion returns the Java version of the toolchain or source compatibility configured in the first
+// existing of files, 0 if there is none
+func gradleJavaVersion(files ...string) (int, error) {
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if m := gradleToolchainRegexp.FindSubmatch(b); m != nil {
+			if version, ok := parseJavaVersion(string(m[1])); ok {
+				return version, nil
 			}
 		}
+		for _, m := range gradleCompatibilityRegexp.FindAllSubmatch(b, -1) {
+			v := string(m[2])
+			if len(m[1]) > 0 {
+				v = strings.ReplaceAll(string(m[1]), "_", ".")
+			}
+			if version, ok := parseJavaVersion(v); ok {
+				return version, nil
+			}
+		}
+		return 0, nil
 	}
+	return 0, nil
+}
 
-	return dslSteps, err
+// parseJavaVersion returns the feature release of a Java version, e.g. 17 for 17 and 8 for 1.8
+func parseJavaVersion(v string) (int, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "1.")
+	major, _, _ := strings.Cut(v, ".")
+	version, err := strconv.Atoi(major)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
 }
@@ -0,0 +1,263 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJavaVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		buildTool string
+		files     map[string]string
+		expected  int
+	}{
+		{
+			name:      "maven release",
+			buildTool: "maven",
+			files: map[string]string{
+				"pom.xml": "<project><properties><maven.compiler.release>17</maven.compiler.release></properties></project>",
+			},
+			expected: 17,
+		},
+		{
+			name:      "maven java.version property reference",
+			buildTool: "maven",
+			files: map[string]string{
+				"pom.xml": "<project><properties><java.version>11</java.version><maven.compiler.source>${java.version}</maven.compiler.source></properties></project>",
+			},
+			expected: 11,
+		},
+		{
+			name:      "maven legacy source",
+			buildTool: "maven",
+			files: map[string]string{
+				"pom.xml": "<project><properties><maven.compiler.source>1.8</maven.compiler.source><maven.compiler.target>1.8</maven.compiler.target></properties></project>",
+			},
+			expected: 8,
+		},
+		{
+			name:      "maven compiler plugin",
+			buildTool: "maven",
+			files: map[string]string{
+				"pom.xml": "<project><build><plugins><plugin><artifactId>maven-compiler-plugin</artifactId><configuration><release>22</release></configuration></plugin></plugins></build></project>",
+			},
+			expected: 25,
+		},
+		{
+			name:      "maven without version",
+			buildTool: "maven",
+			files:     map[string]string{"pom.xml": "<project/>"},
+			expected:  defaultJDK,
+		},
+		{
+			name:      "invalid pom.xml",
+			buildTool: "maven",
+			files:     map[string]string{"pom.xml": "content"},
+			expected:  defaultJDK,
+		},
+		{
+			name:      "gradle toolchain",
+			buildTool: "gradle",
+			files: map[string]string{
+				"build.gradle.kts": "java {\n    toolchain {\n        languageVersion.set(JavaLanguageVersion.of(17))\n    }\n}\n",
+			},
+			expected: 17,
+		},
+		{
+			name:      "gradle kotlin jvm toolchain",
+			buildTool: "gradle",
+			files:     map[string]string{"build.gradle.kts": "kotlin {\n    jvmToolchain(21)\n}\n"},
+			expected:  21,
+		},
+		{
+			name:      "gradle source compatibility",
+			buildTool: "gradle",
+			files:     map[string]string{"build.gradle": "sourceCompatibility = JavaVersion.VERSION_1_8\n"},
+			expected:  8,
+		},
+		{
+			name:      "gradle source compatibility string",
+			buildTool: "gradle",
+			files:     map[string]string{"build.gradle": "java {\n    sourceCompatibility = '11'\n}\n"},
+			expected:  11,
+		},
+		{
+			name:      "gradle without version",
+			buildTool: "gradle",
+			files:     map[string]string{"build.gradle": "plugins { id 'java' }\n"},
+			expected:  defaultJDK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			version, err := javaVersion(dir, tt.buildTool)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestJavaModules(t *testing.T) {
+	tests := []struct {
+		name      string
+		buildTool string
+		files     map[string]string
+		modules   []string
+		parts     []string
+	}{
+		{
+			name:      "maven modules",
+			buildTool: "maven",
+			files: map[string]string{
+				"pom.xml":      "<project><modules><module>core</module><module>app/pom.xml</module><module>../shared</module></modules></project>",
+				"core/pom.xml": "<project><modules><module>api</module></modules></project>",
+			},
+			modules: []string{"core", "core/api", "app"},
+		},
+		{
+			name:      "maven without modules",
+			buildTool: "maven",
+			files:     map[string]string{"pom.xml": "<project/>"},
+		},
+		{
+			name:      "gradle groovy settings",
+			buildTool: "gradle",
+			files: map[string]string{
+				"settings.gradle": "rootProject.name = 'app'\ninclude 'core', ':services:api'\ninclude(\"web\")\nincludeBuild 'build-logic'\n",
+			},
+			modules: []string{"core", "services/api", "web"},
+			parts:   []string{"buildSrc", "build-logic"},
+		},
+		{
+			name:      "gradle kotlin settings",
+			buildTool: "gradle",
+			files: map[string]string{
+				"settings.gradle.kts": "rootProject.name = \"app\"\n\ninclude(\n    \"core\",\n    \"cli\",\n)\n",
+			},
+			modules: []string{"core", "cli"},
+			parts:   []string{"buildSrc"},
+		},
+		{
+			name:      "gradle without settings",
+			buildTool: "gradle",
+			parts:     []string{"buildSrc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700)
+				require.NoError(t, err)
+				err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			modules, parts, err := javaModules(dir, tt.buildTool)
+			require.NoError(t, err)
+			require.Equal(t, tt.modules, modules)
+			require.Equal(t, tt.parts, parts)
+		})
+	}
+}
+
+func TestJVMTechnology(t *testing.T) {
+	tests := []struct {
+		name       string
+		buildTool  string
+		files      map[string]string
+		modules    []string
+		technology string
+		kotlin     bool
+	}{
+		{
+			name:       "java",
+			buildTool:  "maven",
+			files:      map[string]string{"pom.xml": "<project/>", "src/main/java/App.java": ""},
+			technology: "Java",
+		},
+		{
+			name:       "kotlin gradle plugin",
+			buildTool:  "gradle",
+			files:      map[string]string{"build.gradle.kts": "plugins {\n    kotlin(\"jvm\") version \"2.0.0\"\n}\n"},
+			technology: "Kotlin",
+			kotlin:     true,
+		},
+		{
+			name:       "kotlin maven plugin",
+			buildTool:  "maven",
+			files:      map[string]string{"pom.xml": "<project><build><plugins><plugin><artifactId>kotlin-maven-plugin</artifactId></plugin></plugins></build></project>"},
+			technology: "Kotlin",
+			kotlin:     true,
+		},
+		{
+			name:       "kotlin sources",
+			buildTool:  "gradle",
+			files:      map[string]string{"build.gradle": "", "src/main/kotlin/App.kt": ""},
+			technology: "Kotlin",
+			kotlin:     true,
+		},
+		{
+			name:       "kotlin sources in build output",
+			buildTool:  "gradle",
+			files:      map[string]string{"build.gradle": "", "build/generated/App.kt": ""},
+			technology: "Java",
+		},
+		{
+			name:      "android application module",
+			buildTool: "gradle",
+			files: map[string]string{
+				"build.gradle.kts":     "plugins {\n    id(\"com.android.application\") version \"8.5.0\" apply false\n    id(\"org.jetbrains.kotlin.android\") version \"2.0.0\" apply false\n}\n",
+				"app/build.gradle.kts": "plugins {\n    id(\"com.android.application\")\n}\n",
+			},
+			modules:    []string{"app"},
+			technology: "Android",
+			kotlin:     true,
+		},
+		{
+			name:      "android java library",
+			buildTool: "gradle",
+			files: map[string]string{
+				"build.gradle":     "",
+				"lib/build.gradle": "apply plugin: 'com.android.library'\n",
+			},
+			modules:    []string{"lib"},
+			technology: "Android",
+		},
+		{
+			name:       "scala",
+			buildTool:  "sbt",
+			files:      map[string]string{"build.sbt": ""},
+			technology: "Scala",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700)
+				require.NoError(t, err)
+				err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			technology, kotlin, err := jvmTechnology(dir, tt.buildTool, tt.modules)
+			require.NoError(t, err)
+			require.Equal(t, tt.technology, technology)
+			require.Equal(t, tt.kotlin, kotlin)
+		})
+	}
+}
@@ -2,72 +2,317 @@ package generate
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
 	"github.com/calculi-corp/workflow-advisor/pkg/utils"
+	"gopkg.in/yaml.v3"
 )
 
-const nodeImage = "docker://node:21-alpine3.19"
+const (
+	// nodeLTSVersion is the active LTS release, used when the project does not tell which version it needs
+	nodeLTSVersion = 24
+	nodeImage      = "docker://node:%d-alpine"
+	bunImage       = "docker://oven/bun:1"
+	// corepackSetup provides pnpm and yarn berry, corepack is not bundled with node 25 and later anymore
+	corepackSetup = "npm install --global corepack@latest\ncorepack enable"
+)
 
 type javascript struct {
-	jobName string
+	jobName     string
+	denoJobName string
+}
+
+// packageJSON contains the fields of package.json used for detection
+type packageJSON struct {
+	Name           string            `json:"name"`
+	PackageManager string            `json:"packageManager"`
+	Scripts        map[string]string `json:"scripts"`
+	Engines        struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+	Volta struct {
+		Node string `json:"node"`
+	} `json:"volta"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	// Workspaces is either a list of patterns or an object with the patterns in packages
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// nodeLTSCodenames maps the codenames accepted by nvm as lts/<name> to their major version
+var nodeLTSCodenames = map[string]int{
+	"argon":    4,
+	"boron":    6,
+	"carbon":   8,
+	"dubnium":  10,
+	"erbium":   12,
+	"fermium":  14,
+	"gallium":  16,
+	"hydrogen": 18,
+	"iron":     20,
+	"jod":      22,
+	"krypton":  24,
+}
+
+// jsProject holds what was detected about a single package.json project
+type jsProject struct {
+	packageManager jsPackageManager
+	nodeVersion    int
+	pkg            packageJSON
+	workspace      *jsWorkspace
+	typeScript     bool
+	framework      jsFramework
+}
+
+// jsFramework is a frontend or desktop framework recognized by its dependency
+type jsFramework struct {
+	name       string
+	dependency string
+	// build is run with the package manager exec command if package.json has no build script
+	build string
+	// output is the directory the build is written to
+	output string
+}
+
+// jsFrameworks lists the known frameworks, the first one the project depends on is used. Frameworks built on top
+// of others come first.
+var jsFrameworks = []jsFramework{
+	{name: "React Native", dependency: "react-native"},
+	{name: "Electron", dependency: "electron-builder", build: "electron-builder --publish never", output: "dist"},
+	{name: "Electron", dependency: "@electron-forge/cli", build: "electron-forge make", output: "out"},
+	{name: "Electron", dependency: "electron"},
+	{name: "Next.js", dependency: "next", build: "next build", output: ".next"},
+	{name: "Angular", dependency: "@angular/core", build: "ng build", output: "dist"},
+	{name: "Vite", dependency: "vite", build: "vite build", output: "dist"},
+}
+
+// jsWorkspace is a monorepo of several packages, optionally orchestrated by turborepo or nx
+type jsWorkspace struct {
+	// tool is turbo, nx or empty if scripts are run by the package manager
+	tool string
+	// tasks are the turborepo tasks or nx target defaults
+	tasks []string
+	// members are the package directories relative to the workspace root
+	members []string
+	// scripts of all members mapped to the names of the members defining them
+	scripts map[string][]string
+}
+
+// jsScriptSteps lists the steps run from package.json scripts in order, each with the script names it runs
+// by preference
+var jsScriptSteps = []struct {
+	name    string
+	scripts []string
+}{
+	{name: "lint", scripts: []string{"lint"}},
+	{name: "typecheck", scripts: []string{"typecheck", "type-check"}},
+	{name: "build", scripts: []string{"build"}},
+	{name: "test", scripts: []string{"test:ci", "test"}},
+}
+
+// npmPlaceholderTest is part of the test script npm init generates, it always fails
+const npmPlaceholderTest = "Error: no test specified"
+
+// jsPackageManager describes how dependencies are installed and scripts are run
+type jsPackageManager struct {
+	name string
+	// image replaces the node image for package managers shipping their own runtime
+	image string
+	// setup runs before every command
+	setup   string
+	install string
+	run     string
+	// exec runs a binary installed as dependency
+	exec string
+	// workspaceRun runs the script given as %s in every workspace member defining it
+	workspaceRun string
+	// memberRun runs the script given as the second %s in the workspace member named by the first, it is used
+	// for each member defining the script if the package manager has no workspaceRun
+	memberRun string
 }
 
 func init() {
 	registerGenerator("js", &javascript{
-		jobName: "js-build",
+		jobName:     "js-build",
+		denoJobName: "deno-build",
 	})
 }
 
-func (g *javascript) Generate(ctx context.Context, workflowContext *WorkflowContext) error {
-	srcDir := workflowContext.SrcDir
-	exists, err := utils.Stat(filepath.Join(srcDir, "package.json"))
-	if err != nil || !exists {
-		return err
+func (g *javascript) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	// projects are searched in nested directories as well, members of a workspace found before are skipped
+	projects, err := findProjects(srcDir, true, hasAnyFile("package.json", "deno.json", "deno.jsonc"))
+	if err != nil {
+		return nil, err
 	}
 
-	depsStep := dsl.Step{
-		Name: "get dependencies",
-		Uses: nodeImage,
-		Run:  "npm install",
+	var detections []Detection
+	var covered []string
+	for _, project := range projects {
+		if slices.ContainsFunc(covered, func(dir string) bool {
+			return project == dir || isBelow(project, dir)
+		}) {
+			continue
+		}
+
+		projectDir := filepath.Join(srcDir, project)
+
+		isDeno, err := hasAnyFile("deno.json", "deno.jsonc")(projectDir)
+		if err != nil {
+			return nil, err
+		}
+
+		var d Detection
+		if isDeno {
+			d, err = detectDenoProject(srcDir, projectDir)
+		} else {
+			d, err = detectNodeProject(srcDir, projectDir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.Project = project
+		for _, member := range d.Modules {
+			covered = append(covered, filepath.ToSlash(filepath.Join(project, member)))
+		}
+		detections = append(detections, d)
 	}
-	buildStep := dsl.Step{
-		Name: "build",
-		Uses: nodeImage,
-		Run:  "npm run build",
+	return detections, nil
+}
+
+// detectNodeProject detects the package.json project in projectDir
+func detectNodeProject(srcDir, projectDir string) (Detection, error) {
+	pkg, err := readPackageJSON(filepath.Join(projectDir, "package.json"))
+	if err != nil {
+		return Detection{}, err
 	}
-	testStep := dsl.Step{
-		Name: "test",
-		Uses: nodeImage,
-		Run:  "npm run test",
+
+	pm, evidence, err := detectPackageManager(projectDir, pkg)
+	if err != nil {
+		return Detection{}, err
 	}
 
-	yarnLockExists, err := utils.Stat(filepath.Join(srcDir, "yarn.lock"))
+	version, versionFile, err := nodeVersion(srcDir, projectDir, pkg)
 	if err != nil {
-		return err
+		return Detection{}, err
 	}
-	if yarnLockExists {
-		depsStep.Run = "yarn install"
-		buildStep.Run = "yarn run build"
-		testStep.Run = "yarn run test"
+	if versionFile != "" {
+		evidence = append(evidence, versionFile)
 	}
 
-	return g.addJob(workflowContext.Workflow, depsStep, buildStep, testStep)
+	workspace, workspaceEvidence, err := detectWorkspace(projectDir, pkg)
+	if err != nil {
+		return Detection{}, err
+	}
+	evidence = append(evidence, workspaceEvidence...)
+	var modules []string
+	if workspace != nil {
+		modules = workspace.members
+	}
+
+	technology := "JavaScript"
+	tsConfig := filepath.Join(projectDir, "tsconfig.json")
+	isTypeScript, err := utils.Stat(tsConfig)
+	if err != nil {
+		return Detection{}, err
+	}
+	if isTypeScript {
+		technology = "TypeScript"
+		evidence = append(evidence, tsConfig)
+	}
+
+	framework := pkg.framework()
+
+	return Detection{
+		Generator:      "js",
+		Technology:     technology,
+		Version:        strconv.Itoa(version),
+		Framework:      framework.name,
+		BuildOutput:    framework.output,
+		PackageManager: pm.name,
+		Modules:        modules,
+		Evidence:       relPaths(srcDir, append([]string{filepath.Join(projectDir, "package.json")}, evidence...)...),
+		Confidence:     confidenceHigh,
+		details: jsProject{
+			packageManager: pm,
+			nodeVersion:    version,
+			pkg:            pkg,
+			workspace:      workspace,
+			typeScript:     isTypeScript,
+			framework:      framework,
+		},
+	}, nil
 }
 
-func (g *javascript) addJob(wf *dsl.Workflow, steps ...dsl.Step) error {
-	if wf.Jobs == nil {
-		wf.Jobs = make(map[string]dsl.Job)
+func (g *javascript) Generate(ctx context.Context, workflowContext *WorkflowContext, detections []Detection) error {
+	for _, d := range detections {
+		err := g.generateProject(workflowContext, d)
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if _, ok := wf.Jobs[g.jobName]; ok {
-		return fmt.Errorf("error adding job: job %s already exists", g.jobName)
+func (g *javascript) generateProject(workflowContext *WorkflowContext, d Detection) error {
+	if deno, ok := d.details.(denoProject); ok {
+		return g.generateDenoProject(workflowContext, d, deno)
 	}
 
-	wf.Jobs[g.jobName] = dsl.Job{
-		Steps: append(append([]dsl.Step{
+	p, _ := d.details.(jsProject)
+	pm := p.packageManager
+	image := fmt.Sprintf(nodeImage, p.nodeVersion)
+	if pm.image != "" {
+		image = pm.image
+	}
+
+	steps := []dsl.Step{
+		{
+			Name: "get dependencies",
+			Uses: image,
+			Run:  pm.command(pm.install),
+		},
+	}
+	if tool, cmd := p.workspace.toolCommand(pm); cmd != "" {
+		steps = append(steps, dsl.Step{
+			Name: tool,
+			Uses: image,
+			Run:  pm.command(cmd),
+		})
+		return g.addJob(workflowContext, g.jobName, d.Project, steps...)
+	}
+
+	for _, s := range jsScriptSteps {
+		var cmd string
+		if script := p.pkg.findScript(s.scripts...); script != "" {
+			cmd = pm.run + " " + script
+		} else if script := p.workspace.findScript(s.scripts...); script != "" {
+			cmd = p.workspace.runCommand(pm, script)
+		} else if fallback := p.fallbackCommand(s.name); fallback != "" && p.workspace == nil {
+			cmd = pm.exec + " " + fallback
+		} else {
+			continue
+		}
+		steps = append(steps, dsl.Step{
+			Name: s.name,
+			Uses: image,
+			Run:  pm.command(cmd),
+		})
+	}
+
+	return g.addJob(workflowContext, g.jobName, d.Project, steps...)
+}
+
+func (g *javascript) addJob(workflowContext *WorkflowContext, jobName, project string, steps ...dsl.Step) error {
+	return workflowContext.addJob(projectJobName(jobName, project), dsl.Job{
+		Steps: inProject(project, append(append([]dsl.Step{
 			{
 				Name: "checkout",
 				Uses: "cloudbees-io/checkout@v1",
@@ -80,8 +325,531 @@ func (g *javascript) addJob(wf *dsl.Workflow, steps ...dsl.Step) error {
 					"language": "LANGUAGE_JS",
 				},
 			},
-		),
+		)),
+	})
+}
+
+// command prefixes cmd with the package manager setup
+func (pm jsPackageManager) command(cmd string) string {
+	if pm.setup == "" {
+		return cmd
+	}
+	return pm.setup + "\n" + cmd
+}
+
+// fallbackCommand returns the command run by step if package.json has no script for it, the framework build and
+// type checking with the TypeScript compiler
+func (p jsProject) fallbackCommand(step string) string {
+	switch step {
+	case "build":
+		return p.framework.build
+	case "typecheck":
+		if p.typeScript && p.pkg.dependsOn("typescript") {
+			return "tsc --noEmit"
+		}
+	}
+	return ""
+}
+
+// dependsOn returns true if dependency is a dependency or dev dependency of pkg
+func (pkg packageJSON) dependsOn(dependency string) bool {
+	_, ok := pkg.Dependencies[dependency]
+	if !ok {
+		_, ok = pkg.DevDependencies[dependency]
+	}
+	return ok
+}
+
+// framework returns the framework pkg depends on, the zero value if there is none
+func (pkg packageJSON) framework() jsFramework {
+	for _, f := range jsFrameworks {
+		if pkg.dependsOn(f.dependency) {
+			return f
+		}
+	}
+	return jsFramework{}
+}
+
+// findScript returns the first of names defined in the scripts of pkg, the placeholder test script is ignored
+func (pkg packageJSON) findScript(names ...string) string {
+	for _, name := range names {
+		script, ok := pkg.Scripts[name]
+		if ok && strings.TrimSpace(script) != "" && !strings.Contains(script, npmPlaceholderTest) {
+			return name
+		}
+	}
+	return ""
+}
+
+// readPackageJSON parses file, a package.json which can not be parsed is treated as empty
+func readPackageJSON(file string) (packageJSON, error) {
+	var pkg packageJSON
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return pkg, err
+	}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		// ignore package.json in unknown format
+		return packageJSON{}, nil
+	}
+	return pkg, nil
+}
+
+// nodeVersion returns the node major version required by the project in projectDir. Version files of the project
+// and then of srcDir are checked before volta and engines in package.json. The version file used is returned as well.
+func nodeVersion(srcDir, projectDir string, pkg packageJSON) (int, string, error) {
+	dirs := []string{projectDir}
+	if projectDir != srcDir {
+		dirs = append(dirs, srcDir)
+	}
+	for _, dir := range dirs {
+		for _, f := range []string{".nvmrc", ".node-version"} {
+			file := filepath.Join(dir, f)
+			b, err := utils.ReadFileIfExists(file)
+			if err != nil {
+				return 0, "", err
+			}
+			if version, ok := parseNodeVersion(firstLine(string(b))); ok {
+				return version, file, nil
+			}
+		}
+	}
+
+	if version, ok := parseNodeVersion(pkg.Volta.Node); ok {
+		return version, "", nil
+	}
+	if version, ok := nodeRangeVersion(pkg.Engines.Node); ok {
+		return version, "", nil
+	}
+	return nodeLTSVersion, "", nil
+}
+
+// firstLine returns the first line of s which is neither empty nor a comment
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
+// parseNodeVersion returns the major version of a version as written by nvm, e.g. v20.11.0, 22 or lts/iron
+func parseNodeVersion(v string) (int, bool) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch v {
+	case "":
+		return 0, false
+	case "lts", "lts/*", "node", "stable", "latest":
+		return nodeLTSVersion, true
+	}
+	if codename, ok := strings.CutPrefix(v, "lts/"); ok {
+		major, ok := nodeLTSCodenames[codename]
+		return major, ok
+	}
+
+	major, ok, _ := parseNodeMajor(v)
+	return major, ok && major > 0
+}
+
+// parseNodeMajor returns the major version of a semver version, partial is true when only the major version
+// is given, e.g. 20 or 20.x
+func parseNodeMajor(v string) (major int, ok bool, partial bool) {
+	v = strings.TrimPrefix(v, "v")
+	majorStr, rest, _ := strings.Cut(v, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return 0, false, false
+	}
+	minor, _, _ := strings.Cut(rest, ".")
+	return major, true, minor == "" || minor == "x" || minor == "*"
+}
+
+// nodeRangeVersion returns the major version to use for an engines.node semver range. The LTS version is preferred
+// if the range allows it, otherwise the newest allowed major version.
+func nodeRangeVersion(r string) (int, bool) {
+	best := 0
+	for _, alternative := range strings.Split(r, "||") {
+		lower, upper, ok := nodeRangeBounds(alternative)
+		if !ok || lower > upper {
+			continue
+		}
+		if lower <= nodeLTSVersion && nodeLTSVersion <= upper {
+			return nodeLTSVersion, true
+		}
+		candidate := upper
+		if upper == math.MaxInt {
+			candidate = lower
+		}
+		best = max(best, candidate)
+	}
+	return best, best > 0
+}
+
+// nodeRangeBounds returns the lowest and highest major version allowed by a range without alternatives
+func nodeRangeBounds(r string) (int, int, bool) {
+	lower, upper := 0, math.MaxInt
+	fields := strings.Fields(r)
+	// hyphen ranges, e.g. 18 - 20
+	if len(fields) == 3 && fields[1] == "-" {
+		fields = []string{">=" + fields[0], "<=" + fields[2]}
+	}
+
+	// operators separated from their version, e.g. >= 18
+	var comparators []string
+	for i := 0; i < len(fields); i++ {
+		if strings.Trim(fields[i], "<>=^~") == "" && i+1 < len(fields) {
+			comparators = append(comparators, fields[i]+fields[i+1])
+			i++
+			continue
+		}
+		comparators = append(comparators, fields[i])
+	}
+
+	found := false
+	for _, f := range comparators {
+		v := strings.TrimLeft(f, "<>=^~")
+		op := f[:len(f)-len(v)]
+		if v == "*" || v == "x" || v == "X" || v == "" {
+			found = true
+			continue
+		}
+
+		major, ok, partial := parseNodeMajor(v)
+		if !ok {
+			return 0, 0, false
+		}
+		found = true
+
+		switch op {
+		case ">=":
+			lower = max(lower, major)
+		case ">":
+			if partial {
+				major++
+			}
+			lower = max(lower, major)
+		case "<=":
+			upper = min(upper, major)
+		case "<":
+			if partial || strings.HasSuffix(v, ".0.0") {
+				major--
+			}
+			upper = min(upper, major)
+		default:
+			// exact versions, caret and tilde ranges stay within the major version
+			lower = max(lower, major)
+			upper = min(upper, major)
+		}
+	}
+	return lower, upper, found
+}
+
+// detectPackageManager picks the package manager of the project in dir from the packageManager field of
+// package.json and its lockfiles, npm is used when neither tells. Found lockfiles and configuration are returned.
+func detectPackageManager(dir string, pkg packageJSON) (jsPackageManager, []string, error) {
+	var evidence []string
+	found := map[string]bool{}
+	for _, f := range []string{"bun.lock", "bun.lockb", "pnpm-lock.yaml", "yarn.lock", ".yarnrc.yml", "package-lock.json", "npm-shrinkwrap.json"} {
+		path := filepath.Join(dir, f)
+		exists, err := utils.Stat(path)
+		if err != nil {
+			return jsPackageManager{}, nil, err
+		}
+		if exists {
+			found[f] = true
+			evidence = append(evidence, path)
+		}
+	}
+
+	name, version, _ := strings.Cut(pkg.PackageManager, "@")
+	if name == "" {
+		switch {
+		case found["bun.lock"] || found["bun.lockb"]:
+			name = "bun"
+		case found["pnpm-lock.yaml"]:
+			name = "pnpm"
+		case found["yarn.lock"] || found[".yarnrc.yml"]:
+			name = "yarn"
+		default:
+			name = "npm"
+		}
+	}
+
+	switch name {
+	case "bun":
+		return jsPackageManager{
+			name:    "bun",
+			image:   bunImage,
+			install: lockedInstall(found["bun.lock"] || found["bun.lockb"], "bun install --frozen-lockfile", "bun install"),
+			run:     "bun run",
+			exec:    "bunx",
+			// bun skips members without the script
+			workspaceRun: "bun run --filter '*' %s",
+		}, evidence, nil
+	case "pnpm":
+		return jsPackageManager{
+			name:    "pnpm",
+			setup:   corepackSetup,
+			install: lockedInstall(found["pnpm-lock.yaml"], "pnpm install --frozen-lockfile", "pnpm install"),
+			run:     "pnpm run",
+			exec:    "pnpm exec",
+			// pnpm skips members without the script
+			workspaceRun: "pnpm --recursive run %s",
+		}, evidence, nil
+	case "yarn":
+		major, _ := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+		if major >= 2 || (version == "" && found[".yarnrc.yml"]) {
+			return jsPackageManager{
+				name:    "yarn-berry",
+				setup:   corepackSetup,
+				install: lockedInstall(found["yarn.lock"], "yarn install --immutable", "yarn install"),
+				run:     "yarn run",
+				exec:    "yarn",
+				// foreach skips members without the script
+				workspaceRun: "yarn workspaces foreach --all --topological run %s",
+			}, evidence, nil
+		}
+		return jsPackageManager{
+			name:    "yarn",
+			install: lockedInstall(found["yarn.lock"], "yarn install --frozen-lockfile", "yarn install"),
+			run:     "yarn run",
+			exec:    "yarn",
+			// yarn workspaces run of yarn classic fails on members without the script
+			memberRun: "yarn workspace %s run %s",
+		}, evidence, nil
+	default:
+		return jsPackageManager{
+			name:         "npm",
+			install:      lockedInstall(found["package-lock.json"] || found["npm-shrinkwrap.json"], "npm ci", "npm install"),
+			run:          "npm run",
+			exec:         "npx",
+			workspaceRun: "npm run %s --workspaces --if-present",
+		}, evidence, nil
+	}
+}
+
+// lockedInstall returns the install command honoring the lockfile if there is one
+func lockedInstall(hasLockfile bool, locked, unlocked string) string {
+	if hasLockfile {
+		return locked
+	}
+	return unlocked
+}
+
+// detectWorkspace returns the workspace rooted in dir, nil if dir is a single package. Workspace configuration
+// files found are returned as well.
+func detectWorkspace(dir string, pkg packageJSON) (*jsWorkspace, []string, error) {
+	var evidence []string
+	patterns := workspacePatterns(pkg.Workspaces)
+
+	pnpmWorkspace := filepath.Join(dir, "pnpm-workspace.yaml")
+	b, err := utils.ReadFileIfExists(pnpmWorkspace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b != nil {
+		evidence = append(evidence, pnpmWorkspace)
+		var cfg struct {
+			Packages []string `yaml:"packages"`
+		}
+		// ignore pnpm-workspace.yaml in unknown format
+		if yaml.Unmarshal(b, &cfg) == nil {
+			patterns = append(patterns, cfg.Packages...)
+		}
+	}
+
+	var tool string
+	var tasks []string
+	for _, t := range []struct {
+		name string
+		file string
+		keys []string
+	}{
+		{name: "turbo", file: "turbo.json", keys: []string{"tasks", "pipeline"}},
+		{name: "nx", file: "nx.json", keys: []string{"targetDefaults"}},
+	} {
+		file := filepath.Join(dir, t.file)
+		b, err := utils.ReadFileIfExists(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b == nil {
+			continue
+		}
+		evidence = append(evidence, file)
+		tool = t.name
+		tasks = jsonKeys(b, t.keys...)
+		break
 	}
 
+	if len(patterns) == 0 && tool == "" {
+		return nil, evidence, nil
+	}
+
+	members, err := workspaceMembers(dir, patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	scripts := map[string][]string{}
+	for _, member := range members {
+		memberPkg, err := readPackageJSON(filepath.Join(dir, member, "package.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+		name := memberPkg.Name
+		if name == "" {
+			name = member
+		}
+		for script := range memberPkg.Scripts {
+			if memberPkg.findScript(script) != "" {
+				scripts[script] = append(scripts[script], name)
+			}
+		}
+	}
+
+	return &jsWorkspace{
+		tool:    tool,
+		tasks:   tasks,
+		members: members,
+		scripts: scripts,
+	}, evidence, nil
+}
+
+// workspacePatterns returns the member patterns of the workspaces field of package.json
+func workspacePatterns(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var patterns []string
+	if json.Unmarshal(raw, &patterns) == nil {
+		return patterns
+	}
+	var cfg struct {
+		Packages []string `json:"packages"`
+	}
+	if json.Unmarshal(raw, &cfg) == nil {
+		return cfg.Packages
+	}
+	return nil
+}
+
+// workspaceMembers returns the sorted directories relative to dir matching patterns which contain a package.json.
+// Patterns starting with ! exclude directories, ** matches a single directory level only.
+func workspaceMembers(dir string, patterns []string) ([]string, error) {
+	members := map[string]bool{}
+	var excluded []string
+	for _, pattern := range patterns {
+		if p, ok := strings.CutPrefix(pattern, "!"); ok {
+			excluded = append(excluded, filepath.Clean(p))
+			continue
+		}
+		pattern = strings.ReplaceAll(filepath.Clean(pattern), "**", "*")
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			// ignore invalid patterns
+			continue
+		}
+		for _, match := range matches {
+			ok, err := hasAnyFile("package.json")(match)
+			if err != nil {
+				return nil, err
+			}
+			if !ok || slices.Contains(strings.Split(match, string(filepath.Separator)), "node_modules") {
+				continue
+			}
+			rel, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, err
+			}
+			members[filepath.ToSlash(rel)] = true
+		}
+	}
+
+	var res []string
+	for member := range members {
+		if !slices.ContainsFunc(excluded, func(p string) bool {
+			ok, _ := filepath.Match(filepath.ToSlash(p), member)
+			return ok
+		}) {
+			res = append(res, member)
+		}
+	}
+	slices.Sort(res)
+	return res, nil
+}
+
+// jsonKeys returns the keys of the first of the objects named by fields in the JSON object b
+func jsonKeys(b []byte, fields ...string) []string {
+	var obj map[string]map[string]json.RawMessage
+	if json.Unmarshal(b, &obj) != nil {
+		return nil
+	}
+	for _, field := range fields {
+		if len(obj[field]) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(obj[field]))
+		for key := range obj[field] {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		return keys
+	}
 	return nil
 }
+
+// runCommand returns the command running script in the workspace members defining it
+func (w *jsWorkspace) runCommand(pm jsPackageManager, script string) string {
+	if pm.workspaceRun != "" {
+		return fmt.Sprintf(pm.workspaceRun, script)
+	}
+	var cmds []string
+	for _, member := range w.scripts[script] {
+		cmds = append(cmds, fmt.Sprintf(pm.memberRun, member, script))
+	}
+	return strings.Join(cmds, "\n")
+}
+
+// findScript returns the first of names defined by any workspace member
+func (w *jsWorkspace) findScript(names ...string) string {
+	if w == nil {
+		return ""
+	}
+	for _, name := range names {
+		if len(w.scripts[name]) > 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// toolCommand returns the step name and command running all steps through turborepo or nx, an empty command if
+// the workspace is not orchestrated by either or no known task is found
+func (w *jsWorkspace) toolCommand(pm jsPackageManager) (string, string) {
+	if w == nil || w.tool == "" {
+		return "", ""
+	}
+
+	var tasks []string
+	for _, s := range jsScriptSteps {
+		for _, name := range s.scripts {
+			// turborepo only runs tasks configured in turbo.json, nx infers targets from package.json scripts
+			if slices.Contains(w.tasks, name) || (w.tool == "nx" && len(w.scripts[name]) > 0) {
+				tasks = append(tasks, name)
+				break
+			}
+		}
+	}
+	if len(tasks) == 0 {
+		return "", ""
+	}
+
+	switch w.tool {
+	case "turbo":
+		return "turbo run", pm.exec + " turbo run " + strings.Join(tasks, " ")
+	default:
+		return "nx run-many", pm.exec + " nx run-many --targets=" + strings.Join(tasks, ",")
+	}
+}
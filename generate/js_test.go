@@ -0,0 +1,172 @@
+package generate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		pkg      string
+		expected int
+	}{
+		{
+			name:     "default",
+			expected: nodeLTSVersion,
+		},
+		{
+			name:     "nvmrc",
+			files:    map[string]string{".nvmrc": "v20.11.0\n"},
+			expected: 20,
+		},
+		{
+			name:     "nvmrc lts codename",
+			files:    map[string]string{".nvmrc": "# pinned\nlts/hydrogen\n"},
+			expected: 18,
+		},
+		{
+			name:     "nvmrc latest lts",
+			files:    map[string]string{".nvmrc": "lts/*"},
+			expected: nodeLTSVersion,
+		},
+		{
+			name:     "node-version",
+			files:    map[string]string{".node-version": "22.3.0"},
+			expected: 22,
+		},
+		{
+			name:     "nvmrc in source root",
+			files:    map[string]string{"../.nvmrc": "20"},
+			expected: 20,
+		},
+		{
+			name:     "invalid nvmrc",
+			files:    map[string]string{".nvmrc": "system"},
+			expected: nodeLTSVersion,
+		},
+		{
+			name:     "volta",
+			pkg:      `{"volta": {"node": "18.19.0"}}`,
+			expected: 18,
+		},
+		{
+			name:     "engines",
+			pkg:      `{"engines": {"node": "^20.10.0"}}`,
+			expected: 20,
+		},
+		{
+			name:     "nvmrc before engines",
+			files:    map[string]string{".nvmrc": "22"},
+			pkg:      `{"engines": {"node": "^20.10.0"}}`,
+			expected: 22,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			projectDir := filepath.Join(srcDir, "web")
+			for name, content := range tt.files {
+				err := os.MkdirAll(projectDir, 0700)
+				require.NoError(t, err)
+				err = os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			var pkg packageJSON
+			if tt.pkg != "" {
+				err := json.Unmarshal([]byte(tt.pkg), &pkg)
+				require.NoError(t, err)
+			}
+
+			version, _, err := nodeVersion(srcDir, projectDir, pkg)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestNodeRangeVersion(t *testing.T) {
+	tests := []struct {
+		r        string
+		expected int
+		ok       bool
+	}{
+		{r: ">=18", expected: nodeLTSVersion, ok: true},
+		{r: ">= 18 < 21", expected: 20, ok: true},
+		{r: ">=18.0.0 <22.0.0", expected: 21, ok: true},
+		{r: "^20.10.0 || ^22", expected: 22, ok: true},
+		{r: "18.x || 20.x", expected: 20, ok: true},
+		{r: "~22.1", expected: 22, ok: true},
+		{r: "18 - 20", expected: 20, ok: true},
+		{r: ">20", expected: nodeLTSVersion, ok: true},
+		{r: ">=99", expected: 99, ok: true},
+		{r: "*", expected: nodeLTSVersion, ok: true},
+		{r: "", expected: 0, ok: false},
+		{r: "latest", expected: 0, ok: false},
+		{r: ">=22 <20", expected: 0, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.r, func(t *testing.T) {
+			version, ok := nodeRangeVersion(tt.r)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestPackageJSONFramework(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      string
+		expected string
+		output   string
+	}{
+		{
+			name: "none",
+			pkg:  `{"dependencies": {"express": "4.0.0"}}`,
+		},
+		{
+			name:     "vite",
+			pkg:      `{"devDependencies": {"vite": "5.0.0"}}`,
+			expected: "Vite",
+			output:   "dist",
+		},
+		{
+			name:     "angular",
+			pkg:      `{"dependencies": {"@angular/core": "18.0.0"}, "devDependencies": {"vite": "5.0.0"}}`,
+			expected: "Angular",
+			output:   "dist",
+		},
+		{
+			name:     "electron with vite",
+			pkg:      `{"devDependencies": {"electron": "30.0.0", "electron-builder": "24.0.0", "vite": "5.0.0"}}`,
+			expected: "Electron",
+			output:   "dist",
+		},
+		{
+			name:     "react native",
+			pkg:      `{"dependencies": {"react-native": "0.74.0"}}`,
+			expected: "React Native",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pkg packageJSON
+			err := json.Unmarshal([]byte(tt.pkg), &pkg)
+			require.NoError(t, err)
+
+			framework := pkg.framework()
+			require.Equal(t, tt.expected, framework.name)
+			require.Equal(t, tt.output, framework.output)
+		})
+	}
+}
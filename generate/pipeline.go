@@ -8,9 +8,33 @@ type pipeline struct {
 	tasks []Generator
 }
 
+// Detect returns the detections of every task
+func (p *pipeline) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	var detections []Detection
+	for _, task := range p.tasks {
+		d, err := task.Detect(ctx, srcDir)
+		if err != nil {
+			return nil, err
+		}
+		detections = append(detections, d...)
+	}
+
+	return detections, nil
+}
+
+// Generate runs every task which detected something, detections are recorded in the workflow context
 func (p *pipeline) Generate(ctx context.Context, workflowContext *WorkflowContext) error {
 	for _, task := range p.tasks {
-		err := task.Generate(ctx, workflowContext)
+		detections, err := task.Detect(ctx, workflowContext.SrcDir)
+		if err != nil {
+			return err
+		}
+		if len(detections) == 0 {
+			continue
+		}
+		workflowContext.Detections = append(workflowContext.Detections, detections...)
+
+		err = task.Generate(ctx, workflowContext, detections)
 		if err != nil {
 			return err
 		}
@@ -8,25 +8,37 @@ import (
 )
 
 type fakeGen struct {
-	count int
+	detections []Detection
+	count      int
 }
 
-func (g *fakeGen) Generate(ctx context.Context, workflowContext *WorkflowContext) error {
+func (g *fakeGen) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	return g.detections, nil
+}
+
+func (g *fakeGen) Generate(ctx context.Context, workflowContext *WorkflowContext, detections []Detection) error {
 	g.count += 1
 	return nil
 }
 
 func TestPipeline(t *testing.T) {
 	gen := &fakeGen{
+		detections: []Detection{{Generator: "fake"}},
+		count:      0,
+	}
+	undetected := &fakeGen{
 		count: 0,
 	}
 
 	pip := pipeline{
-		tasks: []Generator{gen, gen},
+		tasks: []Generator{gen, gen, undetected},
 	}
 
-	err := pip.Generate(context.Background(), &WorkflowContext{})
+	wc := &WorkflowContext{}
+	err := pip.Generate(context.Background(), wc)
 	require.NoError(t, err)
 
 	require.Equal(t, 2, gen.count)
+	require.Equal(t, 0, undetected.count)
+	require.Len(t, wc.Detections, 2)
 }
@@ -0,0 +1,152 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+	"github.com/calculi-corp/workflow-advisor/pkg/utils"
+)
+
+// rootProject is the relative path of a project located at the root of the source directory
+const rootProject = "."
+
+// ignoredDirs are never searched for projects, hidden directories such as .git and .venv are skipped as well
+var ignoredDirs = map[string]bool{
+	"node_modules":  true,
+	"vendor":        true,
+	"testdata":      true,
+	"venv":          true,
+	"site-packages": true,
+	"__pycache__":   true,
+}
+
+// findProjects walks srcDir and returns the directories, relative to srcDir, for which isProject returns true.
+// If nested is false directories below a found project are not searched, as they are considered part of it.
+// isProject may return filepath.SkipDir to skip a directory and everything below it.
+func findProjects(srcDir string, nested bool, isProject func(dir string) (bool, error)) ([]string, error) {
+	var projects []string
+
+	err := filepath.WalkDir(srcDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != srcDir && isIgnoredDir(d.Name()) {
+			return filepath.SkipDir
+		}
+
+		ok, err := isProject(path)
+		if err != nil {
+			// includes filepath.SkipDir, which skips the directory
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, filepath.ToSlash(rel))
+
+		if !nested {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return projects, err
+}
+
+// isBelow reports whether dir is a directory nested in project, both relative to the source directory
+func isBelow(dir, project string) bool {
+	return dir != project && (project == rootProject || strings.HasPrefix(dir, project+"/"))
+}
+
+func isIgnoredDir(name string) bool {
+	return ignoredDirs[name] || strings.HasPrefix(name, ".")
+}
+
+// hasAnyFile returns a project filter matching directories containing at least one of files
+func hasAnyFile(files ...string) func(dir string) (bool, error) {
+	return func(dir string) (bool, error) {
+		for _, f := range files {
+			exists, err := utils.Stat(filepath.Join(dir, f))
+			if err != nil || exists {
+				return exists, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// hasAllFiles returns a project filter matching directories containing every one of files
+func hasAllFiles(files ...string) func(dir string) (bool, error) {
+	return func(dir string) (bool, error) {
+		for _, f := range files {
+			exists, err := utils.Stat(filepath.Join(dir, f))
+			if err != nil || !exists {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// projectJobName returns the job name for a project, projects in subdirectories get the path appended
+func projectJobName(jobName, project string) string {
+	if project == rootProject {
+		return jobName
+	}
+
+	var sb strings.Builder
+	sb.WriteString(jobName)
+	sb.WriteByte('-')
+	dash := true
+	for _, r := range strings.ToLower(project) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(sb.String(), "-")
+}
+
+// inProject changes directory into the project before running the script of each step
+func inProject(project string, steps []dsl.Step) []dsl.Step {
+	if project == rootProject {
+		return steps
+	}
+
+	res := make([]dsl.Step, 0, len(steps))
+	for _, step := range steps {
+		if step.Run != "" {
+			step.Run = "cd " + shellQuote(project) + "\n" + step.Run
+		}
+		res = append(res, step)
+	}
+	return res
+}
+
+// shellQuote quotes s as a single shell word, words of only safe characters are returned unchanged
+func shellQuote(s string) string {
+	unsafe := strings.ContainsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./@+", r))
+	})
+	if s != "" && !unsafe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,46 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectJobName(t *testing.T) {
+	tests := []struct {
+		project  string
+		expected string
+	}{
+		{project: ".", expected: "go-build"},
+		{project: "services/api", expected: "go-build-services-api"},
+		{project: "Web_App/", expected: "go-build-web-app"},
+		{project: "_internal/cmd", expected: "go-build-internal-cmd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.project, func(t *testing.T) {
+			require.Equal(t, tt.expected, projectJobName("go-build", tt.project))
+		})
+	}
+}
+
+func TestInProject(t *testing.T) {
+	tests := []struct {
+		project  string
+		expected string
+	}{
+		{project: ".", expected: "make"},
+		{project: "services/api", expected: "cd services/api\nmake"},
+		{project: "my app", expected: "cd 'my app'\nmake"},
+		{project: "it's $HOME", expected: "cd 'it'\\''s $HOME'\nmake"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.project, func(t *testing.T) {
+			steps := inProject(tt.project, []dsl.Step{{Name: "checkout"}, {Name: "build", Run: "make"}})
+			require.Empty(t, steps[0].Run)
+			require.Equal(t, tt.expected, steps[1].Run)
+		})
+	}
+}
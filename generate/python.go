@@ -4,74 +4,763 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
 	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 )
 
 const (
-	requirementsTxt    = "requirements.txt"
-	setupPy            = "setup.py"
-	defaultPythonImage = "docker://python:3.13.0a4-alpine3.19"
+	requirementsTxt  = "requirements.txt"
+	setupPy          = "setup.py"
+	pyprojectToml    = "pyproject.toml"
+	pipfile          = "Pipfile"
+	toxIni           = "tox.ini"
+	noxfilePy        = "noxfile.py"
+	pythonTestReport = "test-results/junit.xml"
+	// pythonDefaultVersion is the minor version of Python 3 used when the project does not tell which it needs
+	pythonDefaultVersion = 13
+	// pythonLatestVersion is the minor version of the newest Python 3 release with an official image, used when the
+	// project requires a newer one
+	pythonLatestVersion = 14
+	pythonImage         = "docker://python:3.%d-%s"
+	// pythonMinSources is the number of Python sources from which a directory without packaging files is built,
+	// regardless of the sources in other languages
+	pythonMinSources = 10
 )
 
 type python struct {
 	jobName string
 }
 
+// pythonProject holds what was detected about a single Python project
+type pythonProject struct {
+	tool pythonTool
+	// packaged is set if the project can be built into a distribution
+	packaged bool
+	// tests is the test runner, pytest, tox or nox, empty if the project has no tests
+	tests string
+	// hasPytest is set if pytest is installed with the project dependencies
+	hasPytest bool
+	// toxPosargs is set if the tox configuration passes positional arguments to the test commands
+	toxPosargs bool
+	image      string
+	checks     []pythonCheck
+}
+
+// pythonCheck is a linter or type checker run if the project configures it
+type pythonCheck struct {
+	// step is the name of the step running the check, lint or typecheck
+	step string
+	// tool is the package installing the check and the name of its table in pyproject.toml
+	tool string
+	// files are the configuration files of the tool
+	files []string
+	// sections are the sections configuring the tool in setup.cfg or tox.ini
+	sections []string
+	command  string
+	// needsGlibc is set if the tool runs binaries which are not built for Alpine
+	needsGlibc bool
+}
+
+// pythonTool describes how a project manager installs dependencies and runs commands in the project environment.
+// Environments are created in the project directory, as every step runs in a new container.
+type pythonTool struct {
+	name string
+	// setup runs before every command, e.g. to install the tool
+	setup string
+	// activate runs before every command but the install command
+	activate string
+	install  string
+	// run prefixes commands run in the project environment
+	run string
+	// installsRequirementsTxt is set if pip installs requirements.txt, extras are the optional dependencies pip
+	// installs with the project otherwise
+	installsRequirementsTxt bool
+	extras                  []string
+	// add prefixes the packages installed into the project environment on demand
+	add   string
+	build string
+}
+
+// pyProject contains the fields of pyproject.toml used for detection
+type pyProject struct {
+	BuildSystem struct {
+		Requires     []string `toml:"requires"`
+		BuildBackend string   `toml:"build-backend"`
+	} `toml:"build-system"`
+	Project struct {
+		Name                 string              `toml:"name"`
+		RequiresPython       string              `toml:"requires-python"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	// DependencyGroups contain requirements and tables including other groups
+	DependencyGroups map[string][]any          `toml:"dependency-groups"`
+	Tool             map[string]toml.Primitive `toml:"tool"`
+
+	// the dependencies declared in the tables of the project managers
+	poetry struct {
+		Dependencies    map[string]any `toml:"dependencies"`
+		DevDependencies map[string]any `toml:"dev-dependencies"`
+		Group           map[string]struct {
+			Optional     bool           `toml:"optional"`
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"group"`
+	}
+	pdm struct {
+		DevDependencies map[string][]string `toml:"dev-dependencies"`
+	}
+	uv struct {
+		DevDependencies []string `toml:"dev-dependencies"`
+	}
+}
+
+// pipfileDependencies contains the packages of a Pipfile
+type pipfileDependencies struct {
+	Packages    map[string]any `toml:"packages"`
+	DevPackages map[string]any `toml:"dev-packages"`
+}
+
+// pythonBuildBackends maps PEP 517 build backends to the name reported as build tool
+var pythonBuildBackends = map[string]string{
+	"setuptools.build_meta":            "setuptools",
+	"setuptools.build_meta:__legacy__": "setuptools",
+	"hatchling.build":                  "hatchling",
+	"poetry.core.masonry.api":          "poetry-core",
+	"pdm.backend":                      "pdm-backend",
+	"pdm.pep517.api":                   "pdm-backend",
+	"flit_core.buildapi":               "flit",
+	"maturin":                          "maturin",
+}
+
+// pythonTestExtras are the optional dependencies installed with the project by pip if defined
+var pythonTestExtras = []string{"dev", "test", "tests"}
+
+// pythonChecks are the linters and type checkers in the order they are run
+var pythonChecks = []pythonCheck{
+	{step: "lint", tool: "ruff", files: []string{"ruff.toml", ".ruff.toml"}, command: "ruff check ."},
+	// unlike the other tools flake8 does not skip virtual environments
+	{step: "lint", tool: "flake8", files: []string{".flake8"}, sections: []string{"[flake8]"}, command: "flake8 --extend-exclude=.venv ."},
+	{step: "lint", tool: "black", command: "black --check ."},
+	{step: "lint", tool: "isort", files: []string{".isort.cfg"}, sections: []string{"[isort]", "[tool:isort]"}, command: "isort --check-only ."},
+	{step: "typecheck", tool: "mypy", files: []string{"mypy.ini", ".mypy.ini"}, sections: []string{"[mypy]"}, command: "mypy ."},
+	// the pyright package downloads Node.js with nodeenv, which has no builds for musl
+	{step: "typecheck", tool: "pyright", files: []string{"pyrightconfig.json"}, command: "pyright", needsGlibc: true},
+}
+
+// pythonGlibcPackages are distributed as wheels for glibc based Linux only or fail to build on Alpine, projects
+// depending on them use the Debian based image
+var pythonGlibcPackages = []string{
+	"numpy", "pandas", "scipy", "scikit-learn", "matplotlib", "pyarrow", "torch", "tensorflow", "opencv-python",
+	"psycopg2", "grpcio", "lxml",
+}
+
+// otherSourceExts are the extensions of sources in other languages weighed against the Python sources of
+// directories without packaging files
+var otherSourceExts = map[string]bool{
+	".go": true, ".js": true, ".jsx": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".scala": true, ".cs": true, ".rb": true, ".rs": true, ".php": true,
+	".swift": true, ".c": true, ".cc": true, ".cpp": true, ".h": true, ".hpp": true,
+}
+
+// pythonRequirementNameRe matches the package name at the start of a requirement
+var pythonRequirementNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+// pythonRequiresRe matches python_requires in setup.py and setup.cfg
+var pythonRequiresRe = regexp.MustCompile(`python_requires\s*=\s*["']?([^"'\n]+)`)
+
 func init() {
 	registerGenerator("python", &python{
 		jobName: "python-build",
 	})
 }
 
-func (p *python) Generate(ctx context.Context, wc *WorkflowContext) error {
-	isPython, err := p.containsSource(wc.SrcDir)
+func (p *python) Detect(ctx context.Context, srcDir string) ([]Detection, error) {
+	ignore, err := readGitignore(srcDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !isPython {
-		return nil
+
+	if ignore == nil {
+		ignore = &gitignore{root: srcDir}
+	}
+
+	// projects are searched in nested directories as well, the sources of a nested project are not counted for
+	// the projects containing it
+	isProject := hasAnyFile(requirementsTxt, setupPy, pyprojectToml, pipfile)
+	projects, err := findProjects(srcDir, true, func(dir string) (bool, error) {
+		if ignore.ignored(dir, true) {
+			// the patterns are not matched against the paths below an ignored directory
+			return false, filepath.SkipDir
+		}
+		return isProject(dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	hasPackaging := len(projects) > 0
+	if !hasPackaging {
+		// sources without packaging files are built as a single project
+		projects = []string{rootProject}
+	}
+
+	var detections []Detection
+	for _, project := range projects {
+		projectDir := filepath.Join(srcDir, project)
+		var nested []string
+		for _, dir := range projects {
+			if isBelow(dir, project) {
+				nested = append(nested, dir)
+			}
+		}
+		projectIgnore := ignore.withDirs(nested...)
+
+		sources, err := countSources(projectDir, projectIgnore)
+		if err != nil {
+			return nil, err
+		}
+		if sources.python == 0 || !hasPackaging && !sources.significant() {
+			continue
+		}
+
+		d, err := detectPythonProject(srcDir, projectDir, projectIgnore)
+		if err != nil {
+			return nil, err
+		}
+		d.Project = project
+		d.Evidence = relPaths(srcDir, append([]string{sources.first}, d.Evidence...)...)
+		detections = append(detections, d)
 	}
-	return p.addJobIfNotExists(wc.Workflow, wc.SrcDir)
+	return detections, nil
 }
 
-func (p *python) containsSource(srcDir string) (bool, error) {
-	containsPySource := func(path string) (bool, error) {
-		name := filepath.Base(path)
-		return strings.HasSuffix(name, ".py"), nil
+// detectPythonProject detects the packaging, project manager and Python version of the project in dir, evidence
+// contains the manifests, lockfiles and version files found
+func detectPythonProject(srcDir, dir string, ignore *gitignore) (Detection, error) {
+	d := Detection{
+		Generator:  "python",
+		Technology: "Python",
+		Confidence: confidenceLow,
+	}
+
+	found := map[string]bool{}
+	for _, f := range []string{requirementsTxt, setupPy, pyprojectToml, pipfile, "Pipfile.lock", "poetry.lock", "uv.lock", "pdm.lock"} {
+		path := filepath.Join(dir, f)
+		exists, err := utils.Stat(path)
+		if err != nil {
+			return d, err
+		}
+		if exists {
+			found[f] = true
+			d.Evidence = append(d.Evidence, path)
+			d.Confidence = confidenceHigh
+		}
 	}
-	hasPySource, err := findInDir(srcDir, containsPySource)
+
+	pyproject, err := readPyProject(filepath.Join(dir, pyprojectToml))
 	if err != nil {
-		return false, err
+		return d, err
+	}
+
+	switch {
+	case pyproject.BuildSystem.BuildBackend != "":
+		d.BuildTool = pythonBuildBackends[pyproject.BuildSystem.BuildBackend]
+		if d.BuildTool == "" {
+			d.BuildTool = pyproject.BuildSystem.BuildBackend
+		}
+	case found[setupPy]:
+		d.BuildTool = "setuptools"
+	}
+	packaged := d.BuildTool != ""
+
+	tests, err := detectPythonTests(dir, pyproject, ignore)
+	if err != nil {
+		return d, err
+	}
+	var toxPosargs bool
+	switch tests {
+	case "nox":
+		d.Evidence = append(d.Evidence, filepath.Join(dir, noxfilePy))
+	case "tox":
+		toxConfig := filepath.Join(dir, toxIni)
+		if _, ok := pyproject.Tool["tox"]; ok {
+			toxConfig = filepath.Join(dir, pyprojectToml)
+		} else {
+			d.Evidence = append(d.Evidence, toxConfig)
+		}
+		toxPosargs, err = fileContains(toxConfig, func(text string) bool {
+			return strings.Contains(text, "posargs")
+		})
+		if err != nil {
+			return d, err
+		}
 	}
-	return hasPySource, nil
+
+	version, versionFile, err := pythonVersion(srcDir, dir, pyproject)
+	if err != nil {
+		return d, err
+	}
+	if versionFile != "" {
+		d.Evidence = append(d.Evidence, versionFile)
+	}
+	d.Version = fmt.Sprintf("3.%d", version)
+	checks, err := detectPythonChecks(dir, pyproject)
+	if err != nil {
+		return d, err
+	}
+
+	variant := "alpine"
+	needsGlibc, err := requiresAny(dir, pythonGlibcPackages...)
+	if err != nil {
+		return d, err
+	}
+	if needsGlibc || slices.ContainsFunc(checks, func(c pythonCheck) bool { return c.needsGlibc }) {
+		variant = "slim"
+	}
+
+	tool := detectPythonTool(found, pyproject, packaged)
+	d.PackageManager = tool.name
+	requirements, err := installedRequirements(dir, tool, pyproject)
+	if err != nil {
+		return d, err
+	}
+	hasPytest := slices.ContainsFunc(requirements, func(r string) bool { return requirementName(r) == "pytest" })
+	d.details = pythonProject{
+		tool:       tool,
+		packaged:   packaged,
+		tests:      tests,
+		hasPytest:  hasPytest,
+		toxPosargs: toxPosargs,
+		image:      fmt.Sprintf(pythonImage, version, variant),
+		checks:     checks,
+	}
+	return d, nil
+}
+
+// detectPythonChecks returns the checks configured in pyproject.toml, their own configuration files or the
+// sections of setup.cfg and tox.ini
+func detectPythonChecks(dir string, pyproject pyProject) ([]pythonCheck, error) {
+	var checks []pythonCheck
+	for _, check := range pythonChecks {
+		configured, err := check.configured(dir, pyproject)
+		if err != nil {
+			return nil, err
+		}
+		if configured {
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
 }
 
-func (p *python) addJobIfNotExists(wf *dsl.Workflow, srcDir string) error {
-	if wf.Jobs == nil {
-		wf.Jobs = make(map[string]dsl.Job)
+func (c pythonCheck) configured(dir string, pyproject pyProject) (bool, error) {
+	if _, ok := pyproject.Tool[c.tool]; ok {
+		return true, nil
+	}
+	for _, f := range c.files {
+		exists, err := utils.Stat(filepath.Join(dir, f))
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	for _, f := range []string{"setup.cfg", toxIni} {
+		ok, err := fileContains(filepath.Join(dir, f), func(text string) bool {
+			return slices.Contains(c.sections, strings.TrimSpace(text))
+		})
+		if err != nil || ok {
+			return ok, err
+		}
 	}
+	return false, nil
+}
 
-	if _, ok := wf.Jobs[p.jobName]; ok {
-		return fmt.Errorf("error adding job: job %s already exists", p.jobName)
+// pythonVersion returns the minor version of Python 3 required by the project in dir. Version files of the
+// project and then of srcDir are checked before requires-python and python_requires. The file the version was
+// read from is returned if it is not a manifest. Versions newer than pythonLatestVersion are lowered to it.
+func pythonVersion(srcDir, dir string, pyproject pyProject) (int, string, error) {
+	dirs := []string{dir}
+	if dir != srcDir {
+		dirs = append(dirs, srcDir)
+	}
+	for _, d := range dirs {
+		for _, f := range []string{".python-version", "runtime.txt"} {
+			file := filepath.Join(d, f)
+			b, err := utils.ReadFileIfExists(file)
+			if err != nil {
+				return 0, "", err
+			}
+			// runtime.txt contains the version as python-3.12.1
+			v := strings.TrimPrefix(firstLine(string(b)), "python-")
+			if version, ok, _ := parsePythonMinor(v); ok {
+				return min(version, pythonLatestVersion), file, nil
+			}
+		}
 	}
 
-	steps, err := buildSteps(srcDir)
+	if version, ok := pythonRangeVersion(pyproject.Project.RequiresPython); ok {
+		return min(version, pythonLatestVersion), "", nil
+	}
+	for _, f := range []string{"setup.cfg", setupPy} {
+		b, err := utils.ReadFileIfExists(filepath.Join(dir, f))
+		if err != nil {
+			return 0, "", err
+		}
+		if m := pythonRequiresRe.FindSubmatch(b); m != nil {
+			if version, ok := pythonRangeVersion(string(m[1])); ok {
+				return min(version, pythonLatestVersion), "", nil
+			}
+		}
+	}
+	return pythonDefaultVersion, "", nil
+}
+
+// parsePythonMinor returns the minor version of a Python 3 version such as 3.12.1, 3.12 or 3.12.*, partial is
+// true when the micro version is not given
+func parsePythonMinor(v string) (minor int, ok bool, partial bool) {
+	majorStr, rest, _ := strings.Cut(strings.TrimSpace(v), ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil || major != 3 {
+		return 0, false, false
+	}
+	minorStr, micro, _ := strings.Cut(rest, ".")
+	minor, err = strconv.Atoi(minorStr)
 	if err != nil {
-		return err
+		return 0, false, false
 	}
+	return minor, true, micro == "" || micro == "*"
+}
 
-	wf.Jobs[p.jobName] = dsl.Job{
-		Steps: steps,
+// pythonRangeVersion returns the minor version of Python 3 to use for a PEP 440 version specifier such as
+// ">=3.9,<3.13". The default version is preferred if the specifier allows it, otherwise the closest allowed one.
+func pythonRangeVersion(spec string) (int, bool) {
+	lower, upper := 0, math.MaxInt
+	found := false
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.ReplaceAll(clause, " ", "")
+		v := strings.TrimLeft(clause, "<>=!~^")
+		op := clause[:len(clause)-len(v)]
+		if op == "!=" {
+			continue
+		}
+		if major, err := strconv.Atoi(v); err == nil {
+			// major versions only, e.g. <4 or >=3
+			if major < 3 && strings.HasPrefix(op, ">") || major > 3 && strings.HasPrefix(op, "<") {
+				found = true
+			}
+			continue
+		}
+		minor, ok, partial := parsePythonMinor(v)
+		if !ok {
+			continue
+		}
+		found = true
+
+		switch op {
+		case ">=", "^":
+			lower = max(lower, minor)
+		case ">":
+			if partial {
+				minor++
+			}
+			lower = max(lower, minor)
+		case "<=":
+			upper = min(upper, minor)
+		case "<":
+			if partial || strings.HasSuffix(v, ".0") {
+				minor--
+			}
+			upper = min(upper, minor)
+		case "~=":
+			// ~=3.10 allows any later 3.x, ~=3.10.2 stays within 3.10
+			lower = max(lower, minor)
+			if !partial {
+				upper = min(upper, minor)
+			}
+		default:
+			lower = max(lower, minor)
+			upper = min(upper, minor)
+		}
+	}
+	if !found || lower > upper {
+		return 0, false
+	}
+	return min(max(pythonDefaultVersion, lower), upper), true
+}
+
+// detectPythonTool picks the project manager from lockfiles first and then from its configuration in
+// pyproject.toml, pip is used otherwise
+func detectPythonTool(found map[string]bool, pyproject pyProject, packaged bool) pythonTool {
+	_, hasPoetry := pyproject.Tool["poetry"]
+	_, hasPDM := pyproject.Tool["pdm"]
+	_, hasUV := pyproject.Tool["uv"]
+
+	switch {
+	case found["uv.lock"] || (hasUV && !found["poetry.lock"] && !found["pdm.lock"]):
+		install := "uv sync"
+		if found["uv.lock"] {
+			install += " --locked"
+		}
+		return pythonTool{
+			name:    "uv",
+			setup:   "pip install --quiet uv",
+			install: install,
+			run:     "uv run ",
+			add:     "uv pip install --quiet ",
+			build:   "uv build",
+		}
+	case found["poetry.lock"] || hasPoetry || pyproject.BuildSystem.BuildBackend == "poetry.core.masonry.api":
+		return pythonTool{
+			name:    "poetry",
+			setup:   "pip install --quiet poetry\nexport POETRY_VIRTUALENVS_IN_PROJECT=true",
+			install: "poetry install --no-interaction",
+			run:     "poetry run ",
+			add:     "poetry run pip install --quiet ",
+			build:   "poetry build",
+		}
+	case found["pdm.lock"] || hasPDM:
+		install := "pdm install"
+		if found["pdm.lock"] {
+			install += " --frozen-lockfile"
+		}
+		return pythonTool{
+			name: "pdm",
+			// pdm creates environments without pip unless configured
+			setup:   "pip install --quiet pdm\nexport PDM_VENV_WITH_PIP=true",
+			install: install,
+			run:     "pdm run ",
+			add:     "pdm run pip install --quiet ",
+			build:   "pdm build",
+		}
+	case found[pipfile] || found["Pipfile.lock"]:
+		install := "pipenv install --dev"
+		if found["Pipfile.lock"] {
+			install += " --deploy"
+		}
+		return pythonTool{
+			name:    "pipenv",
+			setup:   "pip install --quiet pipenv\nexport PIPENV_VENV_IN_PROJECT=1",
+			install: install,
+			run:     "pipenv run ",
+			add:     "pipenv run pip install --quiet ",
+			build:   "pip install --quiet build\npipenv run python -m build",
+		}
+	}
+
+	tool := pythonTool{
+		name:  "pip",
+		add:   "pip install --quiet ",
+		build: "pip install build\npython -m build",
+	}
+	var install []string
+	if found[requirementsTxt] {
+		tool.installsRequirementsTxt = true
+		install = append(install, "pip install -r requirements.txt")
+	}
+	if packaged && !found[requirementsTxt] {
+		target := "."
+		for _, extra := range pythonTestExtras {
+			if _, ok := pyproject.Project.OptionalDependencies[extra]; ok {
+				tool.extras = append(tool.extras, extra)
+			}
+		}
+		if len(tool.extras) > 0 {
+			target = `".[` + strings.Join(tool.extras, ",") + `]"`
+		}
+		install = append(install, "pip install "+target)
+	}
+
+	if len(install) > 0 {
+		// a virtual environment in the project keeps the packages for the following steps
+		tool.activate = ". .venv/bin/activate"
+		tool.install = "python -m venv .venv\n. .venv/bin/activate\npython -m pip install --upgrade pip\n" + strings.Join(install, "\n")
+	}
+	return tool
+}
+
+// readPyProject parses file, a missing pyproject.toml or one which can not be parsed is treated as empty
+func readPyProject(file string) (pyProject, error) {
+	var pyproject pyProject
+	b, err := utils.ReadFileIfExists(file)
+	if err != nil || b == nil {
+		return pyproject, err
+	}
+	md, err := toml.Decode(string(b), &pyproject)
+	if err != nil {
+		// ignore pyproject.toml in unknown format
+		return pyProject{}, nil
+	}
+	for name, v := range map[string]any{"poetry": &pyproject.poetry, "pdm": &pyproject.pdm, "uv": &pyproject.uv} {
+		if primitive, ok := pyproject.Tool[name]; ok {
+			// a table in unknown format declares no dependencies
+			_ = md.PrimitiveDecode(primitive, v)
+		}
+	}
+	return pyproject, nil
+}
+
+// installedRequirements returns the requirements installed into the project environment by the install step of
+// tool. Requirements which may be installed are left out, e.g. those of files included by requirements.txt.
+func installedRequirements(dir string, tool pythonTool, pyproject pyProject) ([]string, error) {
+	var requirements []string
+	groups := func(names ...string) {
+		for name, group := range pyproject.DependencyGroups {
+			if len(names) > 0 && !slices.Contains(names, name) {
+				continue
+			}
+			for _, r := range group {
+				// skip tables including other groups
+				if r, ok := r.(string); ok {
+					requirements = append(requirements, r)
+				}
+			}
+		}
+	}
+
+	switch tool.name {
+	case "uv":
+		// uv sync installs the dev group by default, but no extras
+		requirements = append(requirements, pyproject.Project.Dependencies...)
+		requirements = append(requirements, pyproject.uv.DevDependencies...)
+		groups("dev")
+	case "poetry":
+		requirements = append(requirements, pyproject.Project.Dependencies...)
+		requirements = append(requirements, slices.Collect(maps.Keys(pyproject.poetry.Dependencies))...)
+		requirements = append(requirements, slices.Collect(maps.Keys(pyproject.poetry.DevDependencies))...)
+		for _, group := range pyproject.poetry.Group {
+			if !group.Optional {
+				requirements = append(requirements, slices.Collect(maps.Keys(group.Dependencies))...)
+			}
+		}
+	case "pdm":
+		requirements = append(requirements, pyproject.Project.Dependencies...)
+		for _, group := range pyproject.pdm.DevDependencies {
+			requirements = append(requirements, group...)
+		}
+		groups()
+	case "pipenv":
+		b, err := utils.ReadFileIfExists(filepath.Join(dir, pipfile))
+		if err != nil {
+			return nil, err
+		}
+		var pipfile pipfileDependencies
+		// a Pipfile in unknown format declares no dependencies
+		_, _ = toml.Decode(string(b), &pipfile)
+		requirements = append(requirements, slices.Collect(maps.Keys(pipfile.Packages))...)
+		requirements = append(requirements, slices.Collect(maps.Keys(pipfile.DevPackages))...)
+	default:
+		if tool.install == "" {
+			break
+		}
+		if tool.installsRequirementsTxt {
+			b, err := utils.ReadFileIfExists(filepath.Join(dir, requirementsTxt))
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range strings.Split(string(b), "\n") {
+				line = strings.TrimSpace(line)
+				// skip comments and options such as -r other.txt
+				if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "-") {
+					requirements = append(requirements, line)
+				}
+			}
+			break
+		}
+		requirements = append(requirements, pyproject.Project.Dependencies...)
+		for _, extra := range tool.extras {
+			requirements = append(requirements, pyproject.Project.OptionalDependencies[extra]...)
+		}
+	}
+	return requirements, nil
+}
+
+// requirementName returns the normalized name of the package of a requirement such as "pytest[testing]>=8"
+func requirementName(requirement string) string {
+	name := pythonRequirementNameRe.FindString(strings.TrimSpace(requirement))
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+}
+
+// command returns cmds run in the project environment after installing the packages in with
+func (t pythonTool) command(cmds []string, with ...string) string {
+	lines := []string{t.setup, t.activate}
+	if len(with) > 0 {
+		lines = append(lines, t.add+strings.Join(with, " "))
+	}
+	for _, cmd := range cmds {
+		lines = append(lines, t.run+cmd)
+	}
+	return joinLines(lines...)
+}
+
+// joinLines joins the non-empty lines
+func joinLines(lines ...string) string {
+	return strings.Join(slices.DeleteFunc(lines, func(s string) bool { return s == "" }), "\n")
+}
+
+func (p *python) Generate(ctx context.Context, wc *WorkflowContext, detections []Detection) error {
+	for _, d := range detections {
+		err := p.addJob(wc, d)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func buildSteps(srcDir string) ([]dsl.Step, error) {
+// pythonSources counts the Python sources of a project and the sources in other languages
+type pythonSources struct {
+	// first is the path of the first Python source found
+	first  string
+	python int
+	other  int
+}
+
+// countSources counts the sources in dir, ignored directories and files ignored by git are skipped
+func countSources(dir string, ignore *gitignore) (pythonSources, error) {
+	var sources pythonSources
+	err := walkSources(dir, ignore, func(path string) error {
+		ext := filepath.Ext(path)
+		switch {
+		case ext == ".py":
+			if sources.python == 0 {
+				sources.first = path
+			}
+			sources.python++
+		case otherSourceExts[ext]:
+			sources.other++
+		}
+		return nil
+	})
+	return sources, err
+}
+
+// significant reports whether the Python sources of a directory without packaging files make it a Python
+// project rather than incidental scripts, e.g. helpers next to the sources of another language
+func (s pythonSources) significant() bool {
+	return s.python >= pythonMinSources || s.python >= s.other
+}
+
+func (p *python) addJob(wc *WorkflowContext, d Detection) error {
+	project, _ := d.details.(pythonProject)
+	steps, err := buildSteps(project)
+	if err != nil {
+		return err
+	}
+
+	return wc.addJob(projectJobName(p.jobName, d.Project), dsl.Job{
+		Steps: inProject(d.Project, steps),
+	})
+}
+
+func buildSteps(project pythonProject) ([]dsl.Step, error) {
 	steps := []dsl.Step{
 		{
 			Name: "checkout",
@@ -79,10 +768,10 @@ func buildSteps(srcDir string) ([]dsl.Step, error) {
 		},
 	}
 
-	type assembleFunc func(srcDir string) (*dsl.Step, error)
+	type assembleFunc func(project pythonProject) (*dsl.Step, error)
 
-	for _, assemble := range []assembleFunc{installStep, buildStep, testStep, scanStep} {
-		step, err := assemble(srcDir)
+	for _, assemble := range []assembleFunc{installStep, lintStep, typecheckStep, buildStep, testStep, scanStep} {
+		step, err := assemble(project)
 		if err != nil {
 			return steps, err
 		}
@@ -94,7 +783,7 @@ func buildSteps(srcDir string) ([]dsl.Step, error) {
 	return steps, nil
 }
 
-func scanStep(_ string) (*dsl.Step, error) {
+func scanStep(_ pythonProject) (*dsl.Step, error) {
 	return &dsl.Step{
 		Name: "scan",
 		Uses: "cloudbees-io/sonarqube-bundled-sast-scan-code@v2",
@@ -104,116 +793,203 @@ func scanStep(_ string) (*dsl.Step, error) {
 	}, nil
 }
 
-func installStep(srcDir string) (*dsl.Step, error) {
-	hasRequirements, err := utils.Stat(filepath.Join(srcDir, requirementsTxt))
-	if err != nil {
-		return nil, err
+func installStep(project pythonProject) (*dsl.Step, error) {
+	if project.tool.install == "" {
+		return nil, nil
+	}
+	return &dsl.Step{
+		Name: "install packages",
+		Uses: project.image,
+		Run:  joinLines(project.tool.setup, project.tool.install),
+	}, nil
+}
+
+func lintStep(project pythonProject) (*dsl.Step, error) {
+	return checkStep(project, "lint"), nil
+}
+
+func typecheckStep(project pythonProject) (*dsl.Step, error) {
+	return checkStep(project, "typecheck"), nil
+}
+
+// checkStep runs the checks of the project for step. The tools are installed into the project environment first,
+// which keeps the versions of tools declared as dependencies.
+func checkStep(project pythonProject, step string) *dsl.Step {
+	var tools, cmds []string
+	for _, check := range project.checks {
+		if check.step == step {
+			tools = append(tools, check.tool)
+			cmds = append(cmds, check.command)
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
 	}
-	if hasRequirements {
-		return &dsl.Step{
-			Name: "install packages",
-			Uses: defaultPythonImage,
-			Run: `python -m pip install --upgrade pip
-pip install -r requirements.txt`,
-		}, nil
+	return &dsl.Step{
+		Name: step,
+		Uses: project.image,
+		Run:  project.tool.command(cmds, tools...),
 	}
-	return nil, nil
 }
 
-func buildStep(srcDir string) (*dsl.Step, error) {
-	hasSetup, err := utils.Stat(filepath.Join(srcDir, setupPy))
-	if err != nil {
-		return nil, err
+func buildStep(project pythonProject) (*dsl.Step, error) {
+	if !project.packaged {
+		return nil, nil
 	}
-	if hasSetup {
-		return &dsl.Step{
-			Name: "build",
-			Uses: defaultPythonImage,
-			Run: `python -m pip install build
-python -m build --sdist
-python -m build --wheel`,
-		}, nil
+	return &dsl.Step{
+		Name: "build",
+		Uses: project.image,
+		Run:  joinLines(project.tool.setup, project.tool.activate, project.tool.build),
+	}, nil
+}
+
+func testStep(project pythonProject) (*dsl.Step, error) {
+	var run string
+	switch project.tests {
+	case "":
+		return nil, nil
+	case "nox":
+		// sessions inherit the environment, so pytest run by a session writes the report
+		run = joinLines("pip install --quiet nox", `PYTEST_ADDOPTS="--junitxml=`+pythonTestReport+`" nox`)
+	case "tox":
+		run = "tox"
+		if project.toxPosargs {
+			run += " -- --junitxml=" + pythonTestReport
+		}
+		run = joinLines("pip install --quiet tox", run)
+	default:
+		// pytest runs unittest test cases as well
+		var with []string
+		if !project.hasPytest {
+			with = append(with, "pytest")
+		}
+		run = project.tool.command([]string{"python -m pytest --junitxml=" + pythonTestReport}, with...)
 	}
-	return nil, nil
+	return &dsl.Step{
+		Name: "test",
+		Uses: project.image,
+		Run:  run,
+	}, nil
 }
 
-func testStep(srcDir string) (*dsl.Step, error) {
-	requiresPytest, err := fileContains(filepath.Join(srcDir, requirementsTxt), func(t string) bool {
-		return strings.Contains(t, "pytest==") && !strings.HasPrefix("#", t)
-	})
+// detectPythonTests returns the runner of the tests in dir: nox or tox if configured, pytest if configured or
+// test files are found and empty if the project has no tests
+func detectPythonTests(dir string, pyproject pyProject, ignore *gitignore) (string, error) {
+	hasNox, err := utils.Stat(filepath.Join(dir, noxfilePy))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	installPytest := `python -m pip install pytest
-`
-	if requiresPytest {
-		installPytest = ""
+	if hasNox {
+		return "nox", nil
 	}
 
-	hasTests, err := hasTests(srcDir)
+	// tox.ini may only hold the configuration of other tools
+	hasTox, err := fileContains(filepath.Join(dir, toxIni), func(text string) bool {
+		return strings.HasPrefix(text, "[tox]") || strings.HasPrefix(text, "[testenv")
+	})
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if _, ok := pyproject.Tool["tox"]; ok || hasTox {
+		return "tox", nil
+	}
+
+	if _, ok := pyproject.Tool["pytest"]; ok {
+		return "pytest", nil
+	}
+	for _, cfg := range []struct{ file, section string }{
+		{"pytest.ini", "[pytest]"},
+		{toxIni, "[pytest]"},
+		{"setup.cfg", "[tool:pytest]"},
+	} {
+		ok, err := fileContains(filepath.Join(dir, cfg.file), func(text string) bool {
+			return strings.TrimSpace(text) == cfg.section
+		})
+		if err != nil || ok {
+			return "pytest", err
+		}
 	}
 
-	if requiresPytest || hasTests {
-		return &dsl.Step{
-			Name: "test",
-			Uses: defaultPythonImage,
-			Run:  installPytest + "python -m unittest",
-		}, nil
+	found, err := findInDir(dir, ignore, isPythonTest)
+	if err != nil || !found {
+		return "", err
 	}
-	return nil, nil
+	return "pytest", nil
 }
 
-func hasTests(srcDir string) (bool, error) {
-	importsPytest := func(text string) bool {
-		return !strings.HasPrefix("#", text) && strings.Contains("import pytest", text)
+// isPythonTest reports whether path is conftest.py or a test module in the naming used by pytest and unittest
+func isPythonTest(path string) (bool, error) {
+	name := filepath.Base(path)
+	if name == "conftest.py" {
+		return true, nil
 	}
-	isTest := func(path string) (bool, error) {
-		name := filepath.Base(path)
-		if strings.HasSuffix(name, ".py") && strings.Contains(name, "test") {
-			ok, err := fileContains(path, importsPytest)
-			if err != nil {
-				return false, err
-			}
-			if ok {
-				return true, nil
-			}
-		}
+	if !strings.HasSuffix(name, ".py") || !(strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py")) {
 		return false, nil
 	}
+	// test modules contain test functions for pytest or unittest test cases, which pytest runs as well
+	return fileContains(path, func(text string) bool {
+		text = strings.TrimSpace(text)
+		return strings.HasPrefix(text, "def test") || strings.HasPrefix(text, "class Test") ||
+			strings.HasPrefix(text, "import pytest") || strings.HasPrefix(text, "import unittest") ||
+			strings.HasPrefix(text, "from unittest") || strings.HasPrefix(text, "from pytest")
+	})
+}
 
-	if found, err := findInDir(filepath.Join(srcDir, "tests"), isTest); err != nil {
-		return false, err
-	} else {
-		return found, nil
+// requiresAny reports whether any of packages is declared as a dependency in the project manifests
+func requiresAny(dir string, packages ...string) (bool, error) {
+	for _, f := range []string{requirementsTxt, pyprojectToml, pipfile, setupPy, "setup.cfg"} {
+		ok, err := fileContains(filepath.Join(dir, f), func(text string) bool {
+			text = strings.ToLower(strings.TrimSpace(text))
+			// skip comments and table headers such as [tool.pytest.ini_options]
+			if strings.HasPrefix(text, "#") || strings.HasPrefix(text, "[") {
+				return false
+			}
+			return slices.ContainsFunc(packages, func(p string) bool { return strings.Contains(text, p) })
+		})
+		if err != nil || ok {
+			return ok, err
+		}
 	}
+	return false, nil
 }
 
 // findInDir walks a file directory until the provided filter returns true, if filter does not match returns false
-func findInDir(dir string, filter func(path string) (bool, error)) (bool, error) {
+func findInDir(dir string, ignore *gitignore, filter func(path string) (bool, error)) (bool, error) {
 	found := false
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := walkSources(dir, ignore, func(path string) error {
+		ok, err := filter(path)
+		if ok {
+			found = true
+			return filepath.SkipAll
+		}
+		return err
+	})
+	return found, err
+}
+
+// walkSources calls fn for the regular files in dir, skipping ignored directories, virtual environments and files
+// ignored by git
+func walkSources(dir string, ignore *gitignore, fn func(path string) error) error {
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-
-		if info.Mode().IsRegular() {
-			if ok, err := filter(path); err != nil {
-				return err
-			} else if ok {
-				found = true
-				return filepath.SkipAll
+		if d.IsDir() {
+			if path != dir && (isIgnoredDir(d.Name()) || ignore.ignored(path, true)) {
+				return filepath.SkipDir
 			}
+			return nil
 		}
-		return nil
+		if !d.Type().IsRegular() || ignore.ignored(path, false) {
+			return nil
+		}
+		return fn(path)
 	})
 
 	if os.IsNotExist(err) {
-		return false, nil
+		return nil
 	}
-	return found, err
+	return err
 }
 
 // fileContains will read a file until the provided filter returns true, if filter does not match returns false
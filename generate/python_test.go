@@ -0,0 +1,503 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPythonProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		buildTool      string
+		packageManager string
+		install        string
+	}{
+		{
+			name:           "requirements.txt",
+			files:          map[string]string{"requirements.txt": "requests\n"},
+			packageManager: "pip",
+			install:        "python -m venv .venv\n. .venv/bin/activate\npython -m pip install --upgrade pip\npip install -r requirements.txt",
+		},
+		{
+			name:           "setuptools pyproject.toml with extras",
+			files:          map[string]string{"pyproject.toml": "[project]\nname = \"app\"\n\n[project.optional-dependencies]\ndev = [\"ruff\"]\ntest = [\"pytest\"]\n\n[build-system]\nrequires = [\"setuptools\"]\nbuild-backend = \"setuptools.build_meta\"\n"},
+			buildTool:      "setuptools",
+			packageManager: "pip",
+			install:        "python -m venv .venv\n. .venv/bin/activate\npython -m pip install --upgrade pip\npip install \".[dev,test]\"",
+		},
+		{
+			name:           "flit",
+			files:          map[string]string{"pyproject.toml": "[build-system]\nrequires = [\"flit_core\"]\nbuild-backend = \"flit_core.buildapi\"\n"},
+			buildTool:      "flit",
+			packageManager: "pip",
+			install:        "python -m venv .venv\n. .venv/bin/activate\npython -m pip install --upgrade pip\npip install .",
+		},
+		{
+			name:           "poetry without lockfile",
+			files:          map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"app\"\n"},
+			packageManager: "poetry",
+			install:        "poetry install --no-interaction",
+		},
+		{
+			name: "uv lockfile",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[build-system]\nrequires = [\"hatchling\"]\nbuild-backend = \"hatchling.build\"\n",
+				"uv.lock":        "version = 1\n",
+			},
+			buildTool:      "hatchling",
+			packageManager: "uv",
+			install:        "uv sync --locked",
+		},
+		{
+			name: "pdm backend",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[tool.pdm]\n\n[build-system]\nrequires = [\"pdm-backend\"]\nbuild-backend = \"pdm.backend\"\n",
+			},
+			buildTool:      "pdm-backend",
+			packageManager: "pdm",
+			install:        "pdm install",
+		},
+		{
+			name:           "pipenv lockfile",
+			files:          map[string]string{"Pipfile": "[packages]\n", "Pipfile.lock": "{}"},
+			packageManager: "pipenv",
+			install:        "pipenv install --dev --deploy",
+		},
+		{
+			name:           "invalid pyproject.toml",
+			files:          map[string]string{"pyproject.toml": "content"},
+			packageManager: "pip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			d, err := detectPythonProject(dir, dir, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.buildTool, d.BuildTool)
+			require.Equal(t, tt.packageManager, d.PackageManager)
+			project := d.details.(pythonProject)
+			require.Equal(t, tt.install, project.tool.install)
+			require.Equal(t, tt.buildTool != "", project.packaged)
+		})
+	}
+}
+
+func TestDetectPythonTests(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name:     "no tests",
+			files:    map[string]string{"app.py": "print('hello')\n"},
+			expected: "",
+		},
+		{
+			name:     "nox",
+			files:    map[string]string{"noxfile.py": "import nox\n", "tests/test_app.py": "import pytest\n"},
+			expected: "nox",
+		},
+		{
+			name:     "tox",
+			files:    map[string]string{"tox.ini": "[tox]\nenvlist = py312\n"},
+			expected: "tox",
+		},
+		{
+			name:     "tox in pyproject.toml",
+			files:    map[string]string{"pyproject.toml": "[tool.tox]\nenv_list = [\"3.13\"]\n"},
+			expected: "tox",
+		},
+		{
+			name:     "tox.ini with flake8 configuration only",
+			files:    map[string]string{"tox.ini": "[flake8]\nmax-line-length = 120\n"},
+			expected: "",
+		},
+		{
+			name:     "pytest.ini",
+			files:    map[string]string{"pytest.ini": "[pytest]\naddopts = -ra\n"},
+			expected: "pytest",
+		},
+		{
+			name:     "pytest in pyproject.toml",
+			files:    map[string]string{"pyproject.toml": "[tool.pytest.ini_options]\ntestpaths = [\"tests\"]\n"},
+			expected: "pytest",
+		},
+		{
+			name:     "pytest in setup.cfg",
+			files:    map[string]string{"setup.cfg": "[metadata]\nname = app\n\n[tool:pytest]\ntestpaths = tests\n"},
+			expected: "pytest",
+		},
+		{
+			name:     "conftest.py",
+			files:    map[string]string{"src/conftest.py": "\n"},
+			expected: "pytest",
+		},
+		{
+			name:     "pytest test functions",
+			files:    map[string]string{"src/app/app_test.py": "def test_app():\n    assert True\n"},
+			expected: "pytest",
+		},
+		{
+			name:     "unittest test cases",
+			files:    map[string]string{"app/test_app.py": "from unittest import TestCase\n"},
+			expected: "pytest",
+		},
+		{
+			name:     "test named module without tests",
+			files:    map[string]string{"test_data.py": "DATA = []\n"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700)
+				require.NoError(t, err)
+				err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			pyproject, err := readPyProject(filepath.Join(dir, pyprojectToml))
+			require.NoError(t, err)
+			runner, err := detectPythonTests(dir, pyproject, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, runner)
+		})
+	}
+}
+
+func TestPythonVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected int
+	}{
+		{
+			name:     "no version",
+			files:    map[string]string{"requirements.txt": "requests\n"},
+			expected: pythonDefaultVersion,
+		},
+		{
+			name:     ".python-version",
+			files:    map[string]string{".python-version": "3.11.9\n", "pyproject.toml": "[project]\nrequires-python = \">=3.12\"\n"},
+			expected: 11,
+		},
+		{
+			name:     "runtime.txt",
+			files:    map[string]string{"runtime.txt": "python-3.10.14\n"},
+			expected: 10,
+		},
+		{
+			name:     "unsupported .python-version",
+			files:    map[string]string{".python-version": "pypy3.10\n"},
+			expected: pythonDefaultVersion,
+		},
+		{
+			name:     "requires-python allowing the default version",
+			files:    map[string]string{"pyproject.toml": "[project]\nrequires-python = \">=3.9\"\n"},
+			expected: pythonDefaultVersion,
+		},
+		{
+			name:     "requires-python excluding the default version",
+			files:    map[string]string{"pyproject.toml": "[project]\nrequires-python = \">=3.8, <3.12\"\n"},
+			expected: 11,
+		},
+		{
+			name:     "requires-python newer than the default version",
+			files:    map[string]string{"pyproject.toml": "[project]\nrequires-python = \">3.13\"\n"},
+			expected: 14,
+		},
+		{
+			name:     "unreleased .python-version",
+			files:    map[string]string{".python-version": "3.16\n"},
+			expected: pythonLatestVersion,
+		},
+		{
+			name:     "requires-python newer than the latest release",
+			files:    map[string]string{"pyproject.toml": "[project]\nrequires-python = \">=3.16\"\n"},
+			expected: pythonLatestVersion,
+		},
+		{
+			name:     "requires-python compatible release",
+			files:    map[string]string{"pyproject.toml": "[project]\nrequires-python = \"~=3.10.4\"\n"},
+			expected: 10,
+		},
+		{
+			name:     "requires-python exact",
+			files:    map[string]string{"pyproject.toml": "[project]\nrequires-python = \"==3.12.*\"\n"},
+			expected: 12,
+		},
+		{
+			name:     "setup.py python_requires",
+			files:    map[string]string{"setup.py": "setup(\n    name='app',\n    python_requires='>=3.6, <3.10',\n)\n"},
+			expected: 9,
+		},
+		{
+			name:     "setup.cfg python_requires",
+			files:    map[string]string{"setup.cfg": "[options]\npython_requires = ==3.8.*\n"},
+			expected: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			pyproject, err := readPyProject(filepath.Join(dir, pyprojectToml))
+			require.NoError(t, err)
+			version, _, err := pythonVersion(dir, dir, pyproject)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestPythonDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name:     "scripts",
+			files:    map[string]string{"main.py": "", "lib/util.py": ""},
+			expected: []string{"."},
+		},
+		{
+			name:     "helper script in another language",
+			files:    map[string]string{"main.go": "", "cmd/tool/main.go": "", "scripts/release.py": ""},
+			expected: nil,
+		},
+		{
+			name: "many scripts next to another language",
+			files: map[string]string{
+				"main.go": "", "a.go": "", "b.go": "", "c.go": "", "d.go": "", "e.go": "", "f.go": "", "g.go": "",
+				"h.go": "", "i.go": "", "j.go": "", "k.go": "",
+				"scripts/0.py": "", "scripts/1.py": "", "scripts/2.py": "", "scripts/3.py": "", "scripts/4.py": "",
+				"scripts/5.py": "", "scripts/6.py": "", "scripts/7.py": "", "scripts/8.py": "", "scripts/9.py": "",
+			},
+			expected: []string{"."},
+		},
+		{
+			name:     "packaged helper script",
+			files:    map[string]string{"main.go": "", "a.go": "", "tools/requirements.txt": "", "tools/gen.py": ""},
+			expected: []string{"tools"},
+		},
+		{
+			name: "virtual environments and dependencies",
+			files: map[string]string{
+				"main.js": "",
+				".venv/lib/python3.13/site-packages/a.py":      "",
+				"venv/lib/python3.13/site-packages/b.py":       "",
+				"node_modules/node-gyp/gyp/gyp_main.py":        "",
+				"vendor/lib/c.py":                              "",
+				"env/lib/python3.13/site-packages/d.py":        "",
+				"env/lib/python3.13/site-packages/setup.py":    "",
+				"lib/python3.13/site-packages/pkg/__init__.py": "",
+			},
+			expected: nil,
+		},
+		{
+			name: "ignored by git",
+			files: map[string]string{
+				".gitignore":                 "/generated/\n*_pb2.py\n",
+				"main.go":                    "",
+				"api_pb2.py":                 "",
+				"generated/setup.py":         "",
+				"generated/client.py":        "",
+				"generated/requirements.txt": "",
+			},
+			expected: nil,
+		},
+		{
+			name: "packaging below a directory ignored by git",
+			files: map[string]string{
+				".gitignore":               "third_party/\n",
+				"main.go":                  "",
+				"third_party/lib/setup.py": "",
+				"third_party/lib/lib.py":   "",
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700)
+				require.NoError(t, err)
+				err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			detections, err := (&python{}).Detect(context.Background(), dir)
+			require.NoError(t, err)
+			var projects []string
+			for _, d := range detections {
+				projects = append(projects, d.Project)
+			}
+			require.Equal(t, tt.expected, projects)
+		})
+	}
+}
+
+func TestDetectPythonChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name:  "no configuration",
+			files: map[string]string{"requirements.txt": "ruff\n"},
+		},
+		{
+			name: "pyproject.toml",
+			files: map[string]string{
+				"pyproject.toml": "[tool.ruff]\nline-length = 100\n\n[tool.black]\n\n[tool.isort]\nprofile = \"black\"\n\n[tool.mypy]\nstrict = true\n\n[tool.pyright]\n",
+			},
+			expected: []string{"ruff", "black", "isort", "mypy", "pyright"},
+		},
+		{
+			name:     "configuration files",
+			files:    map[string]string{".ruff.toml": "", ".flake8": "", ".isort.cfg": "", "mypy.ini": "", "pyrightconfig.json": "{}"},
+			expected: []string{"ruff", "flake8", "isort", "mypy", "pyright"},
+		},
+		{
+			name:     "setup.cfg",
+			files:    map[string]string{"setup.cfg": "[metadata]\nname = app\n\n[flake8]\nmax-line-length = 100\n\n[mypy]\n"},
+			expected: []string{"flake8", "mypy"},
+		},
+		{
+			name:     "tox.ini",
+			files:    map[string]string{"tox.ini": "[tox]\nenvlist = py312\n\n[isort]\nprofile = black\n"},
+			expected: []string{"isort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			pyproject, err := readPyProject(filepath.Join(dir, pyprojectToml))
+			require.NoError(t, err)
+			checks, err := detectPythonChecks(dir, pyproject)
+			require.NoError(t, err)
+			var tools []string
+			for _, c := range checks {
+				tools = append(tools, c.tool)
+			}
+			require.Equal(t, tt.expected, tools)
+		})
+	}
+}
+
+func TestPythonHasPytest(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected bool
+	}{
+		{
+			name:     "requirements.txt",
+			files:    map[string]string{"requirements.txt": "requests\npytest==8.1.1\n"},
+			expected: true,
+		},
+		{
+			name: "requirements.txt with pytest in an extra",
+			files: map[string]string{
+				"requirements.txt": "requests\n",
+				"pyproject.toml":   "[project]\nname = \"app\"\n\n[project.optional-dependencies]\ntest = [\"pytest\"]\n\n[build-system]\nbuild-backend = \"setuptools.build_meta\"\n",
+			},
+		},
+		{
+			name: "pip extras",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[project.optional-dependencies]\ntest = [\"pytest>=8\"]\n\n[build-system]\nbuild-backend = \"setuptools.build_meta\"\n",
+			},
+			expected: true,
+		},
+		{
+			name: "uv dev group",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[dependency-groups]\ndev = [\"pytest\", {include-group = \"lint\"}]\nlint = [\"ruff\"]\n",
+				"uv.lock":        "",
+			},
+			expected: true,
+		},
+		{
+			name: "uv with pytest in an extra",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[project.optional-dependencies]\ntest = [\"pytest\"]\n",
+				"uv.lock":        "",
+			},
+		},
+		{
+			name: "poetry group",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = \"app\"\n\n[tool.poetry.group.test.dependencies]\npytest = \"^8\"\n",
+			},
+			expected: true,
+		},
+		{
+			name: "poetry optional group",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = \"app\"\n\n[tool.poetry.group.test]\noptional = true\n\n[tool.poetry.group.test.dependencies]\npytest = \"^8\"\n",
+			},
+		},
+		{
+			name: "pdm dev dependencies",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[tool.pdm.dev-dependencies]\ntest = [\"pytest\"]\n",
+			},
+			expected: true,
+		},
+		{
+			name:     "pipenv dev packages",
+			files:    map[string]string{"Pipfile": "[packages]\nrequests = \"*\"\n\n[dev-packages]\npytest = \"*\"\n"},
+			expected: true,
+		},
+		{
+			name:  "pytest configuration only",
+			files: map[string]string{"pyproject.toml": "[tool.pytest.ini_options]\naddopts = \"-ra\"\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640)
+				require.NoError(t, err)
+			}
+
+			d, err := detectPythonProject(dir, dir, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d.details.(pythonProject).hasPytest)
+		})
+	}
+}
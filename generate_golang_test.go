@@ -23,8 +23,76 @@ func Test_GenerateGolang(t *testing.T) {
 	assertWorkflow(t, workflowDir, "golang_smoke.yaml")
 }
 
-func Test_GenerateGolang_NotDetected(t *testing.T) {
-	// Golang detector requires go.mod and go.sum, should not generate golang job
+func Test_GenerateGolang_Lint(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.sum"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"go.mod":        "module example.com/m\n\ngo 1.25.1\n",
+		".golangci.yml": "version: \"2\"\n",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "golang_lint.yaml")
+}
+
+func Test_GenerateGolang_LintLatestGo(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.sum"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"go.mod":        "module example.com/m\n\ngo 1.26.0\n",
+		".golangci.yml": "version: \"2\"\n",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "golang_lint_go126.yaml")
+}
+
+func Test_GenerateGolang_Rerun(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum"})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	cmd = GenerateCommand()
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	require.EqualError(t, err, "error adding job: job go-build already exists")
+
+	for _, strategy := range []string{"skip", "replace", "merge-steps"} {
+		cmd = GenerateCommand()
+		cmd.SetArgs(append(args, "--on-conflict", strategy))
+		err = cmd.Execute()
+		require.NoError(t, err)
+
+		assertWorkflow(t, workflowDir, "golang_smoke.yaml")
+	}
+}
+
+func Test_GenerateGolang_NoGoSum(t *testing.T) {
+	// modules without dependencies have no go.sum
 	workflowDir, srcDir := initTest(t, []string{"go.mod"})
 	args := []string{
 		"--workflow", path.Join(workflowDir, wfFilename),
@@ -37,5 +105,115 @@ func Test_GenerateGolang_NotDetected(t *testing.T) {
 	err := cmd.Execute()
 	require.NoError(t, err)
 
+	assertWorkflow(t, workflowDir, "golang_smoke.yaml")
+}
+
+func Test_GenerateGolang_Workspace(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"go.work":            "go 1.24\n\nuse (\n\t./api\n\t./lib // shared code\n)\n",
+		"api/go.mod":         "module example.com/api\n\ngo 1.24\n",
+		"api/go.sum":         "",
+		"lib/go.mod":         "module example.com/lib\n\ngo 1.25.0\n",
+		"tools/lint/go.mod":  "module example.com/tools\n\ngo 1.23\n",
+		"tools/lint/main.go": "package main",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "golang_workspace.yaml")
+}
+
+func Test_GenerateGolang_WorkspaceLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "workspace configuration",
+			config:   ".golangci.yml",
+			expected: "golang_workspace_lint.yaml",
+		},
+		{
+			name:     "module configuration",
+			config:   "b/.golangci.yml",
+			expected: "golang_workspace_module_lint.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflowDir, srcDir := initTest(t, []string{})
+			writeSrcFiles(t, srcDir, map[string]string{
+				"go.work":  "go 1.25\n\nuse (\n\t./a\n\t./b\n)\n",
+				"a/go.mod": "module example.com/a\n\ngo 1.25\n",
+				"b/go.mod": "module example.com/b\n\ngo 1.25\n",
+				tt.config:  "version: \"2\"\n",
+			})
+			args := []string{
+				"--workflow", path.Join(workflowDir, wfFilename),
+				"--src", srcDir,
+				"--generator", "go",
+			}
+
+			cmd := GenerateCommand()
+			cmd.SetArgs(args)
+			err := cmd.Execute()
+			require.NoError(t, err)
+
+			assertWorkflow(t, workflowDir, tt.expected)
+		})
+	}
+}
+
+func Test_GenerateGolang_Binaries(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.sum", "Dockerfile", ".goreleaser.yaml"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"go.mod":                   "module example.com/service/v2\n\ngo 1.26\n",
+		"gen.go":                   "//go:build ignore\n\npackage main\n",
+		"cmd/api/main.go":          "package main\n\nfunc main() {}\n",
+		"cmd/api/main_test.go":     "package main_test\n",
+		"cmd/worker/worker.go":     "// Command worker processes jobs.\npackage main\n",
+		"internal/store/store.go":  "package store\n",
+		"tools/go.mod":             "module example.com/service/tools\n",
+		"tools/generate/main.go":   "package main\n",
+		"testdata/fixture/main.go": "package main\n",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "golang_binaries.yaml")
+}
+
+func Test_GenerateGolang_NotDetected(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.sum", "main.go"})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
 	assertWorkflow(t, workflowDir, "undetected_smoke.yaml")
 }
@@ -7,8 +7,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+const buildTestPackageJSON = `{"scripts": {"build": "tsc", "test": "jest"}}`
+
 func Test_GenerateJavaScript_npm(t *testing.T) {
-	workflowDir, srcDir := initTest(t, []string{"package.json"})
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": buildTestPackageJSON,
+	})
 	args := []string{
 		"--workflow", path.Join(workflowDir, wfFilename),
 		"--src", srcDir,
@@ -24,7 +29,10 @@ func Test_GenerateJavaScript_npm(t *testing.T) {
 }
 
 func Test_GenerateJavaScript_yarn(t *testing.T) {
-	workflowDir, srcDir := initTest(t, []string{"package.json", "yarn.lock"})
+	workflowDir, srcDir := initTest(t, []string{"yarn.lock"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": buildTestPackageJSON,
+	})
 	args := []string{
 		"--workflow", path.Join(workflowDir, wfFilename),
 		"--src", srcDir,
@@ -54,3 +62,269 @@ func Test_GenerateJavaScript_NotDetected(t *testing.T) {
 
 	assertWorkflow(t, workflowDir, "undetected_smoke.yaml")
 }
+
+func Test_GenerateJavaScript_npmCI(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"package-lock.json"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": buildTestPackageJSON,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-npm-ci.yaml")
+}
+
+func Test_GenerateJavaScript_pnpm(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"pnpm-lock.yaml"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": buildTestPackageJSON,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-pnpm.yaml")
+}
+
+func Test_GenerateJavaScript_bun(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"bun.lockb"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": buildTestPackageJSON,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-bun.yaml")
+}
+
+func Test_GenerateJavaScript_yarnBerry(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"yarn.lock"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": `{"packageManager": "yarn@4.1.0", "scripts": {"build": "tsc", "test": "jest"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-yarn-berry.yaml")
+}
+
+func Test_GenerateJavaScript_Scripts(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"package-lock.json"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": `{"scripts": {"start": "node index.js", "lint": "eslint .", "type-check": "tsc --noEmit", "test": "jest", "test:ci": "jest --ci"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-scripts.yaml")
+}
+
+func Test_GenerateJavaScript_PlaceholderTest(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-no-scripts.yaml")
+}
+
+func Test_GenerateJavaScript_NodeVersion(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"package-lock.json"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": `{"engines": {"node": ">=18"}, "scripts": {"build": "tsc", "test": "jest"}}`,
+		".nvmrc":       "v20.11.0\n",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-node-version.yaml")
+}
+
+func Test_GenerateJavaScript_Workspaces(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"package-lock.json"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json":            `{"workspaces": ["packages/*"], "scripts": {"lint": "eslint ."}}`,
+		"packages/a/package.json": `{"scripts": {"build": "tsc", "test": "jest"}}`,
+		"packages/b/package.json": `{"scripts": {"test": "jest"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-workspaces.yaml")
+}
+
+func Test_GenerateJavaScript_YarnClassicWorkspaces(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"yarn.lock"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json":            `{"private": true, "workspaces": ["packages/*"]}`,
+		"packages/a/package.json": `{"name": "@app/a", "scripts": {"build": "tsc", "test": "jest"}}`,
+		"packages/b/package.json": `{"name": "@app/b", "scripts": {"test": "jest"}}`,
+		"packages/c/package.json": `{"name": "@app/c"}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-yarn-workspaces.yaml")
+}
+
+func Test_GenerateJavaScript_Turborepo(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"pnpm-lock.yaml"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json":               `{"scripts": {"build": "turbo run build"}}`,
+		"pnpm-workspace.yaml":        "packages:\n  - apps/*\n  - packages/*\n",
+		"turbo.json":                 `{"tasks": {"build": {}, "test": {}, "dev": {"cache": false}}}`,
+		"apps/web/package.json":      `{"scripts": {"build": "next build", "lint": "next lint"}}`,
+		"packages/ui/package.json":   `{"scripts": {"test": "vitest run"}}`,
+		"packages/docs/README.md":    "",
+		"apps/web/node_modules/.bin": "",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-turbo.yaml")
+}
+
+func Test_GenerateJavaScript_Nx(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"package-lock.json"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json":             `{"workspaces": {"packages": ["libs/*"]}}`,
+		"nx.json":                  `{"targetDefaults": {"build": {"dependsOn": ["^build"]}}}`,
+		"libs/core/package.json":   `{"scripts": {"test": "jest"}}`,
+		"libs/legacy/package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-nx.yaml")
+}
+
+func Test_GenerateJavaScript_TypeScriptFramework(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"package-lock.json", "tsconfig.json"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json": `{"scripts": {"dev": "next dev"}, "dependencies": {"next": "15.0.0", "react": "19.0.0"}, "devDependencies": {"typescript": "5.6.0"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-typescript-nextjs.yaml")
+}
+
+func Test_GenerateJavaScript_Deno(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"api/deno.json":       `{"tasks": {"build": "deno run -A build.ts", "test": {"command": "deno test -A", "dependencies": ["build"]}}}`,
+		"api/deno.lock":       "{}",
+		"web/package.json":    `{"scripts": {"build": "vite build"}}`,
+		"web/deno.jsonc":      "// deno configuration\n{}",
+		"worker/package.json": `{"scripts": {"build": "tsc"}}`,
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "js-deno.yaml")
+}
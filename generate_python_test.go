@@ -38,6 +38,10 @@ func Test_GeneratePython(t *testing.T) {
 					path: "requirements.txt",
 					data: []byte("pytest==8.1.1"),
 				},
+				{
+					path: "test_hello.py",
+					data: []byte("def test_hello():\n    assert True\n"),
+				},
 			},
 			expected: "python_requirements_pytest.yaml",
 		},
@@ -56,6 +60,134 @@ func Test_GeneratePython(t *testing.T) {
 			testFiles: []string{"setup.py"},
 			expected:  "python_setup.yaml",
 		},
+		{
+			name:      "poetry",
+			testFiles: []string{"hello.py", "poetry.lock"},
+			srcContents: []srcContents{
+				{
+					path: "pyproject.toml",
+					data: []byte("[tool.poetry]\nname = \"hello\"\nversion = \"0.1.0\"\n\n[build-system]\nrequires = [\"poetry-core\"]\nbuild-backend = \"poetry.core.masonry.api\"\n"),
+				},
+				{
+					path: "tests/test_hello.py",
+					data: []byte("import pytest"),
+				},
+			},
+			expected: "python_poetry.yaml",
+		},
+		{
+			name:      "uv",
+			testFiles: []string{"hello.py", "uv.lock"},
+			srcContents: []srcContents{
+				{
+					path: "pyproject.toml",
+					data: []byte("[project]\nname = \"hello\"\nversion = \"0.1.0\"\n\n[build-system]\nrequires = [\"hatchling\"]\nbuild-backend = \"hatchling.build\"\n"),
+				},
+			},
+			expected: "python_uv.yaml",
+		},
+		{
+			name:      "pdm",
+			testFiles: []string{"hello.py", "pdm.lock"},
+			srcContents: []srcContents{
+				{
+					path: "pyproject.toml",
+					data: []byte("[project]\nname = \"hello\"\nversion = \"0.1.0\"\n\n[tool.pdm]\ndistribution = false\n"),
+				},
+			},
+			expected: "python_pdm.yaml",
+		},
+		{
+			name:      "pipenv",
+			testFiles: []string{"hello.py", "Pipfile", "Pipfile.lock"},
+			expected:  "python_pipenv.yaml",
+		},
+		{
+			name:      "pyproject.toml with test extras",
+			testFiles: []string{"hello.py"},
+			srcContents: []srcContents{
+				{
+					path: "pyproject.toml",
+					data: []byte("[project]\nname = \"hello\"\nversion = \"0.1.0\"\n\n[project.optional-dependencies]\ntest = [\"pytest\"]\n\n[build-system]\nrequires = [\"hatchling\"]\nbuild-backend = \"hatchling.build\"\n"),
+				},
+			},
+			expected: "python_pyproject_extras.yaml",
+		},
+		{
+			name:      "unittest tests",
+			testFiles: []string{"requirements.txt"},
+			srcContents: []srcContents{
+				{
+					path: "app/hello.py",
+					data: []byte("content"),
+				},
+				{
+					path: "app/test_hello.py",
+					data: []byte("import unittest\n"),
+				},
+			},
+			expected: "python_unittest.yaml",
+		},
+		{
+			name:      "tox",
+			testFiles: []string{"hello.py", "setup.py"},
+			srcContents: []srcContents{
+				{
+					path: "tox.ini",
+					data: []byte("[tox]\nenvlist = py312\n\n[testenv]\ndeps = pytest\ncommands = pytest {posargs}\n"),
+				},
+			},
+			expected: "python_tox.yaml",
+		},
+		{
+			name:      "nox",
+			testFiles: []string{"hello.py", "requirements.txt", "noxfile.py"},
+			expected:  "python_nox.yaml",
+		},
+		{
+			name:      "requires-python with glibc dependencies",
+			testFiles: []string{"hello.py"},
+			srcContents: []srcContents{
+				{
+					path: "pyproject.toml",
+					data: []byte("[project]\nname = \"hello\"\nrequires-python = \">=3.9,<3.13\"\ndependencies = [\"pandas>=2\"]\n\n[build-system]\nrequires = [\"setuptools\"]\nbuild-backend = \"setuptools.build_meta\"\n"),
+				},
+			},
+			expected: "python_requires_python.yaml",
+		},
+		{
+			name:      "lint and type check",
+			testFiles: []string{"hello.py", "uv.lock"},
+			srcContents: []srcContents{
+				{
+					path: "pyproject.toml",
+					data: []byte("[project]\nname = \"hello\"\n\n[dependency-groups]\ndev = [\"ruff\", \"mypy\"]\n\n[tool.ruff]\nline-length = 100\n\n[tool.mypy]\nstrict = true\n"),
+				},
+			},
+			expected: "python_checks.yaml",
+		},
+		{
+			name:      "pyright",
+			testFiles: []string{"hello.py", "requirements.txt"},
+			srcContents: []srcContents{
+				{
+					path: "pyrightconfig.json",
+					data: []byte("{\"typeCheckingMode\": \"strict\"}\n"),
+				},
+			},
+			expected: "python_pyright.yaml",
+		},
+		{
+			name:      "setup.cfg lint configuration",
+			testFiles: []string{"hello.py", "requirements.txt"},
+			srcContents: []srcContents{
+				{
+					path: "setup.cfg",
+					data: []byte("[flake8]\nmax-line-length = 100\n\n[isort]\nprofile = black\n"),
+				},
+			},
+			expected: "python_setup_cfg_lint.yaml",
+		},
 		{
 			name:      "python undetected",
 			testFiles: []string{},
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"log"
 	"os"
 	"path"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/calculi-corp/workflow-advisor/generate"
 	"github.com/calculi-corp/workflow-advisor/pkg/utils"
 )
 
@@ -39,6 +42,270 @@ func Test_GenerateMultiLanguage(t *testing.T) {
 	assertWorkflow(t, workflowDir, "smoke.yaml")
 }
 
+func Test_GenerateJSONOutput(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum"})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+		"--generator", "js",
+		"--output", "json",
+	}
+
+	var stdout bytes.Buffer
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	var out generate.Output
+	err = json.Unmarshal(stdout.Bytes(), &out)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path.Join(workflowDir, wfFilename))
+	require.NoError(t, err)
+	require.Equal(t, string(b), out.Workflow)
+
+	require.Equal(t, []string{"Go"}, out.Technologies)
+	require.Equal(t, []string{"go"}, out.Generators)
+	require.Equal(t, []generate.Detection{
+		{
+			Generator:      "go",
+			Technology:     "Go",
+			Project:        ".",
+			BuildTool:      "go",
+			Version:        "1.26",
+			PackageManager: "go modules",
+			Evidence:       []string{"go.mod", "go.sum"},
+			Confidence:     1,
+		},
+	}, out.Detections)
+}
+
+func Test_GenerateMonorepo(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"services/api/go.mod":               "module api",
+		"services/api/go.sum":               "",
+		"services/worker/go.mod":            "module worker",
+		"services/worker/go.sum":            "",
+		"web/package.json":                  `{"scripts": {"build": "vite build", "test": "vitest run"}}`,
+		"web/node_modules/dep/package.json": "{}",
+		"backend/pom.xml":                   "<project><modules><module>module</module></modules></project>",
+		"backend/module/pom.xml":            "<project/>",
+	})
+
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+		"--generator", "js",
+		"--generator", "java",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "monorepo.yaml")
+}
+
+func Test_GenerateMonorepoRootManifest(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"package.json":          `{"private": true, "scripts": {"prepare": "husky"}, "devDependencies": {"husky": "^9"}}`,
+		"web/package.json":      `{"scripts": {"build": "vite build", "test": "vitest run"}}`,
+		"api/package.json":      `{"scripts": {"test": "node --test"}}`,
+		"requirements.txt":      "requests\n",
+		"scripts/release.py":    "import requests\n",
+		"svc/pyproject.toml":    "[project]\nname = \"svc\"\n",
+		"svc/m1.py":             "",
+		"svc/m2.py":             "",
+		"svc/tests/test_m1.py":  "import pytest\n",
+		"tools/pyproject.toml":  "[project]\nname = \"tools\"\n",
+		"tools/docs/README.txt": "",
+	})
+
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "js",
+		"--generator", "python",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "monorepo_root_manifest.yaml")
+}
+
+func Test_GenerateAuto(t *testing.T) {
+	tests := []struct {
+		name           string
+		extraArgs      []string
+		expectedOutput string
+	}{
+		{
+			name:           "generator omitted",
+			expectedOutput: "auto.yaml",
+		},
+		{
+			name:           "auto generator",
+			extraArgs:      []string{"--generator", "auto"},
+			expectedOutput: "auto.yaml",
+		},
+		{
+			name:           "auto generator with exclude",
+			extraArgs:      []string{"-g", "auto", "--exclude", "js"},
+			expectedOutput: "golang_smoke.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum", "package.json"})
+			args := []string{
+				"--workflow", path.Join(workflowDir, wfFilename),
+				"--src", srcDir,
+			}
+
+			cmd := GenerateCommand()
+			cmd.SetArgs(append(args, tt.extraArgs...))
+			err := cmd.Execute()
+			require.NoError(t, err)
+
+			assertWorkflow(t, workflowDir, tt.expectedOutput)
+		})
+	}
+}
+
+func Test_GenerateExcludeUnknown(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum"})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--exclude", "rust",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.EqualError(t, err, "can not exclude generators with name 'rust', no such generator")
+}
+
+func Test_GenerateDryRun(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum"})
+	workflowPath := path.Join(workflowDir, wfFilename)
+	args := []string{
+		"--workflow", workflowPath,
+		"--src", srcDir,
+		"--generator", "go",
+		"--dry-run",
+	}
+
+	var stdout bytes.Buffer
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	_, err = os.Stat(workflowPath)
+	require.True(t, os.IsNotExist(err), "workflow file must not be written")
+
+	expected, err := os.ReadFile(path.Join(testdata, "golang_smoke.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, string(expected), stdout.String())
+}
+
+func Test_GenerateDiff(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum"})
+	workflowPath := path.Join(workflowDir, wfFilename)
+	current := `apiVersion: automation.cloudbees.io/v1alpha1
+kind: workflow
+name: build
+
+on:
+  push:
+    branches:
+      - '**'
+`
+	err := os.WriteFile(workflowPath, []byte(current), 0640)
+	require.NoError(t, err)
+
+	args := []string{
+		"--workflow", workflowPath,
+		"--src", srcDir,
+		"--generator", "go",
+		"--diff",
+	}
+
+	var stdout bytes.Buffer
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(workflowPath)
+	require.NoError(t, err)
+	require.Equal(t, current, string(b), "workflow file must not be modified")
+
+	require.Equal(t, `--- `+workflowPath+`
++++ `+workflowPath+`
+@@ -6,3 +6,30 @@
+   push:
+     branches:
+       - '**'
++
++jobs:
++  go-build:
++    steps:
++      - name: checkout
++        uses: cloudbees-io/checkout@v1
++      - name: vet
++        uses: docker://golang:1.26
++        run: go vet ./...
++      - name: test
++        uses: docker://golang:1.26
++        run: go test -race -covermode=atomic -coverprofile=coverage.out ./...
++      - name: build
++        uses: docker://golang:1.26
++        run: go build ./...
++      - id: coverage
++        name: coverage report
++        uses: docker://golang:1.26
++        run: go tool cover -func=coverage.out > "$CLOUDBEES_OUTPUTS/report"
++      - name: publish coverage
++        uses: cloudbees-io/publish-evidence-item@v1
++        with:
++          content: ${{ steps.coverage.outputs.report }}
++      - name: scan
++        uses: cloudbees-io/sonarqube-bundled-sast-scan-code@v2
++        with:
++          language: LANGUAGE_GO
+`, stdout.String())
+}
+
+func Test_GenerateUnsupportedOutput(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"go.mod", "go.sum"})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "go",
+		"--output", "xml",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "unsupported output format 'xml'")
+}
+
 func Test_JavaGenerator(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -86,6 +353,77 @@ func Test_JavaGenerator(t *testing.T) {
 	}
 }
 
+func Test_JavaGenerator_JDK(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{"gradlew"})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"build.gradle": "java {\n    toolchain {\n        languageVersion = JavaLanguageVersion.of(17)\n    }\n}\n",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "java",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "java_gradle_jdk17.yaml")
+}
+
+func Test_JavaGenerator_MultiModule(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"pom.xml":                     "<project><modules><module>core</module><module>app</module></modules></project>",
+		"core/pom.xml":                "<project/>",
+		"app/pom.xml":                 "<project/>",
+		"tools/gradlew":               "",
+		"tools/settings.gradle":       "include 'cli'\n",
+		"tools/cli/build.gradle":      "",
+		"tools/buildSrc/build.gradle": "",
+		"examples/hello/pom.xml":      "<project/>",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "java",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "java_multi_module.yaml")
+}
+
+func Test_JavaGenerator_JVMLanguages(t *testing.T) {
+	workflowDir, srcDir := initTest(t, []string{})
+	writeSrcFiles(t, srcDir, map[string]string{
+		"service/gradlew":                   "",
+		"service/build.gradle.kts":          "plugins {\n    kotlin(\"jvm\") version \"2.0.0\"\n}\n",
+		"analytics/build.sbt":               "scalaVersion := \"3.4.2\"\n",
+		"mobile/gradlew":                    "",
+		"mobile/build.gradle":               "",
+		"mobile/settings.gradle":            "include ':app'\n",
+		"mobile/app/build.gradle":           "plugins {\n    id 'com.android.application'\n}\n",
+		"mobile/app/src/main/java/App.java": "",
+	})
+	args := []string{
+		"--workflow", path.Join(workflowDir, wfFilename),
+		"--src", srcDir,
+		"--generator", "java",
+	}
+
+	cmd := GenerateCommand()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assertWorkflow(t, workflowDir, "java_jvm_languages.yaml")
+}
+
 func initTest(t *testing.T, testFiles []string) (workflowDir string, srcDir string) {
 	t.Helper()
 
@@ -113,6 +451,20 @@ func initTest(t *testing.T, testFiles []string) (workflowDir string, srcDir stri
 	return workflowDir, srcDir
 }
 
+// writeSrcFiles writes files with content to srcDir, creating parent directories
+func writeSrcFiles(t *testing.T, srcDir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		fp := path.Join(srcDir, name)
+		err := os.MkdirAll(path.Dir(fp), 0700)
+		require.NoError(t, err, "error creating directory path")
+
+		err = os.WriteFile(fp, []byte(content), 0640)
+		require.NoError(t, err, "error writing file contents")
+	}
+}
+
 func assertWorkflow(t *testing.T, workflowDir string, expectedFilename string) {
 	t.Helper()
 
@@ -3,7 +3,9 @@ module github.com/calculi-corp/workflow-advisor
 go 1.26.2
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/calculi-corp/dsl-engine-cli v0.0.0-20240229142136-dc77ca79f006
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2
 	github.com/spf13/cobra v1.10.2
 	github.com/stretchr/testify v1.11.1
 	gopkg.in/yaml.v3 v3.0.1
@@ -12,6 +14,5 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 )
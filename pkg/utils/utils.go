@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/calculi-corp/dsl-engine-cli/pkg/dsl"
+	"github.com/pmezard/go-difflib/difflib"
 	"gopkg.in/yaml.v3"
 )
 
@@ -183,3 +184,32 @@ func UnmarshalWorkflow(path string) (*dsl.Workflow, error) {
 	}
 	return workflow, nil
 }
+
+// ReadFileIfExists returns the content of file, or nil if it does not exist
+func ReadFileIfExists(file string) ([]byte, error) {
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// UnifiedDiff returns the unified diff turning current into proposed content of file, empty if they are equal
+func UnifiedDiff(file string, current, proposed []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(current),
+		B:        splitLines(proposed),
+		FromFile: file,
+		ToFile:   file,
+		Context:  3,
+	})
+}
+
+// splitLines splits b into lines keeping line endings, unlike difflib.SplitLines no empty line is added at the end
+func splitLines(b []byte) []string {
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
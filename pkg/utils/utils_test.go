@@ -61,3 +61,13 @@ func TestMarshalUnmarshal(t *testing.T) {
 
 	require.Equal(t, workflow, got)
 }
+
+func TestUnifiedDiff(t *testing.T) {
+	d, err := UnifiedDiff("workflow.yaml", []byte("a\nb\n"), []byte("a\nc\n"))
+	require.NoError(t, err)
+	require.Equal(t, "--- workflow.yaml\n+++ workflow.yaml\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n", d)
+
+	d, err = UnifiedDiff("workflow.yaml", []byte("a\n"), []byte("a\n"))
+	require.NoError(t, err)
+	require.Empty(t, d)
+}